@@ -0,0 +1,81 @@
+// Command gen scaffolds project code.
+//
+//	go run ./cmd/gen module invoice --fields "number:string,amount:decimal,paid:bool"
+//
+// generates the model, DTOs, repository, handler, service, route and SQL
+// migration of a module built on the generic CRUD layer, and registers it in
+// factory.Factory and delivery.HTTP.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const usage = `usage: go run ./cmd/gen module <name> [--fields "name:type,..."] [--force]
+
+field types: string, text, int, int64, float, decimal, bool, time, date
+`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "module" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := runModule(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+}
+
+func runModule(args []string) error {
+	var (
+		fields string
+		force  bool
+		root   string
+	)
+	fs := flag.NewFlagSet("module", flag.ContinueOnError)
+	fs.StringVar(&fields, "fields", "", `comma separated fields, e.g. "number:string,amount:decimal"`)
+	fs.BoolVar(&force, "force", false, "overwrite existing files")
+	fs.StringVar(&root, "root", ".", "project root")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+
+	// accept flags both before and after the module name
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing module name")
+	}
+	name := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %s", strings.Join(fs.Args(), " "))
+	}
+
+	m, err := newModule(name, fields)
+	if err != nil {
+		return err
+	}
+	m.reuseVersion(root)
+	files, err := m.render()
+	if err != nil {
+		return err
+	}
+	if err = writeFiles(root, files, force); err != nil {
+		return err
+	}
+	if err = m.register(root); err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Println("created", f.path)
+	}
+	return nil
+}
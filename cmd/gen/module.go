@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var identPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// fieldType describes how a --fields type maps to Go, SQL and the DTO filter.
+// Filters bound by echo (slices) are applied with IN, the others only take
+// operator conditions.
+type fieldType struct {
+	GoType     string
+	SQLType    string
+	FilterType string
+	Ops        string
+	Example    string
+}
+
+var fieldTypes = map[string]fieldType{
+	"string":  {GoType: "string", SQLType: "VARCHAR(255) NOT NULL DEFAULT ''", FilterType: "[]string", Ops: "eq,ne,in,nin,like,ilike", Example: "text"},
+	"text":    {GoType: "string", SQLType: "TEXT NOT NULL DEFAULT ''", FilterType: "*string", Ops: "eq,ne,like,ilike", Example: "text"},
+	"int":     {GoType: "int", SQLType: "INTEGER NOT NULL DEFAULT 0", FilterType: "[]int", Ops: "eq,ne,in,nin,gt,gte,lt,lte", Example: "1"},
+	"int64":   {GoType: "int64", SQLType: "BIGINT NOT NULL DEFAULT 0", FilterType: "[]int64", Ops: "eq,ne,in,nin,gt,gte,lt,lte", Example: "1"},
+	"float":   {GoType: "float64", SQLType: "DOUBLE PRECISION NOT NULL DEFAULT 0", FilterType: "*float64", Ops: "eq,ne,gt,gte,lt,lte", Example: "1.5"},
+	"decimal": {GoType: "float64", SQLType: "NUMERIC(18,2) NOT NULL DEFAULT 0", FilterType: "*float64", Ops: "eq,ne,gt,gte,lt,lte", Example: "1000.50"},
+	"bool":    {GoType: "bool", SQLType: "BOOLEAN NOT NULL DEFAULT FALSE", FilterType: "*bool", Ops: "eq,ne", Example: "true"},
+	"time":    {GoType: "*time.Time", SQLType: "TIMESTAMPTZ", FilterType: "*time.Time", Ops: "eq,gt,gte,lt,lte,null", Example: "2024-01-01T10:00:00Z"},
+	"date":    {GoType: "*time.Time", SQLType: "DATE", FilterType: "*time.Time", Ops: "eq,gt,gte,lt,lte,null", Example: "2024-01-01T00:00:00Z"},
+}
+
+// reservedFields are declared by abstraction.Entity and abstraction.Tenant.
+var reservedFields = map[string]bool{
+	"id": true, "created_date": true, "created_by": true, "modified_date": true, "modified_by": true, "tenant_id": true,
+}
+
+type field struct {
+	fieldType
+	Name   string
+	Pascal string
+	Type   string
+}
+
+// Required reports whether create and update payloads must carry the field.
+func (f field) Required() bool {
+	return f.Type == "string" || f.Type == "text"
+}
+
+// Bound reports whether echo binds the filter field, see
+// abstraction.ParseConditions.
+func (f field) Bound() bool {
+	return strings.HasPrefix(f.FilterType, "[]")
+}
+
+type module struct {
+	Name    string // snake case, e.g. sales_order
+	Pascal  string // SalesOrder
+	Camel   string // salesOrder
+	Package string // salesorder
+	Table   string // m_sales_order
+	Route   string // /sales-order
+	Title   string // Sales Order
+	Fields  []field
+	Version string
+}
+
+func newModule(name, fields string) (*module, error) {
+	name = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(name, "-", "_")))
+	if !identPattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid module name %q, use snake_case", name)
+	}
+	m := &module{
+		Name:    name,
+		Pascal:  pascal(name),
+		Package: strings.ReplaceAll(name, "_", ""),
+		Table:   "m_" + name,
+		Route:   "/" + strings.ReplaceAll(name, "_", "-"),
+		Title:   pascalWords(name),
+		Version: time.Now().UTC().Format("20060102150405"),
+	}
+	m.Camel = strings.ToLower(m.Pascal[:1]) + m.Pascal[1:]
+
+	seen := map[string]bool{}
+	for _, item := range strings.Split(fields, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid field %q, use name:type", item)
+		}
+		fieldName, typ := strings.ToLower(strings.TrimSpace(parts[0])), strings.ToLower(strings.TrimSpace(parts[1]))
+		if !identPattern.MatchString(fieldName) {
+			return nil, fmt.Errorf("invalid field name %q, use snake_case", fieldName)
+		}
+		if reservedFields[fieldName] {
+			return nil, fmt.Errorf("field %q is already declared by abstraction.Entity or abstraction.Tenant", fieldName)
+		}
+		if seen[fieldName] {
+			return nil, fmt.Errorf("duplicate field %q", fieldName)
+		}
+		ft, ok := fieldTypes[typ]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q of field %q", typ, fieldName)
+		}
+		seen[fieldName] = true
+		m.Fields = append(m.Fields, field{fieldType: ft, Name: fieldName, Pascal: pascal(fieldName), Type: typ})
+	}
+	if len(m.Fields) == 0 {
+		return nil, errors.New("a module needs at least one field, see --fields")
+	}
+	return m, nil
+}
+
+// HasTime reports whether the model and DTO import time.
+func (m *module) HasTime() bool {
+	for _, f := range m.Fields {
+		if strings.Contains(f.GoType, "time.") {
+			return true
+		}
+	}
+	return false
+}
+
+// migrationDir holds the SQL migrations of the default PostgreSQL connection.
+var migrationDir = filepath.Join("pkg", "database", "migrations", "pgsql_db_baf")
+
+// reuseVersion keeps the version of a migration generated earlier for the
+// module, so that --force rewrites it instead of adding another one.
+func (m *module) reuseVersion(root string) {
+	matches, _ := filepath.Glob(filepath.Join(root, migrationDir, "*_create_"+m.Table+".up.sql"))
+	if len(matches) > 0 {
+		m.Version = strings.SplitN(filepath.Base(matches[0]), "_", 2)[0]
+	}
+}
+
+type file struct {
+	path    string
+	content []byte
+}
+
+func (m *module) render() ([]file, error) {
+	targets := []struct{ tmpl, path string }{
+		{"model.go.tmpl", filepath.Join("internal", "model", m.Name+".go")},
+		{"dto.go.tmpl", filepath.Join("internal", "dto", m.Name+".go")},
+		{"repository.go.tmpl", filepath.Join("internal", "repository", m.Name+".go")},
+		{"service.go.tmpl", filepath.Join("internal", "app", m.Package, "service.go")},
+		{"handler.go.tmpl", filepath.Join("internal", "app", m.Package, "handler.go")},
+		{"route.go.tmpl", filepath.Join("internal", "app", m.Package, "route.go")},
+		{"migration.up.sql.tmpl", filepath.Join(migrationDir, m.Version+"_create_"+m.Table+".up.sql")},
+		{"migration.down.sql.tmpl", filepath.Join(migrationDir, m.Version+"_create_"+m.Table+".down.sql")},
+	}
+
+	tmpl, err := template.ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]file, 0, len(targets))
+	for _, t := range targets {
+		var buf bytes.Buffer
+		if err = tmpl.ExecuteTemplate(&buf, t.tmpl, m); err != nil {
+			return nil, fmt.Errorf("render %s: %w", t.tmpl, err)
+		}
+		content := buf.Bytes()
+		if strings.HasSuffix(t.path, ".go") {
+			if content, err = format.Source(content); err != nil {
+				return nil, fmt.Errorf("format %s: %w", t.path, err)
+			}
+		}
+		files = append(files, file{path: t.path, content: content})
+	}
+	return files, nil
+}
+
+func writeFiles(root string, files []file, force bool) error {
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(filepath.Join(root, f.path)); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite", f.path)
+			}
+		}
+	}
+	for _, f := range files {
+		path := filepath.Join(root, f.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, f.content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// register wires the module in factory.Factory and delivery.HTTP at their
+// gen: marker comments. It does nothing when the module is already wired.
+func (m *module) register(root string) error {
+	factoryPath := filepath.Join(root, "internal", "factory", "factory.go")
+	if err := edit(factoryPath, func(src string) (string, error) {
+		if strings.Contains(src, "repository.New"+m.Pascal+"(") {
+			return src, nil
+		}
+		src, err := insertBefore(src, "// gen:repository-field", fmt.Sprintf("%sRepository repository.%s\n", m.Pascal, m.Pascal))
+		if err != nil {
+			return "", err
+		}
+		return insertBefore(src, "// gen:repository", fmt.Sprintf("f.%sRepository = repository.New%s(f.DB)\n", m.Pascal, m.Pascal))
+	}); err != nil {
+		return err
+	}
+
+	deliveryPath := filepath.Join(root, "internal", "delivery", "http.go")
+	return edit(deliveryPath, func(src string) (string, error) {
+		importPath := strconv.Quote("boilerplate/internal/app/" + m.Package)
+		if strings.Contains(src, importPath) {
+			return src, nil
+		}
+		src, err := insertBefore(src, "// gen:route", fmt.Sprintf("%s.NewHandler(f).Route(e.Group(%q))\n", m.Package, m.Route))
+		if err != nil {
+			return "", err
+		}
+		return addImport(src, importPath)
+	})
+}
+
+func edit(path string, fn func(src string) (string, error)) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	src, err := fn(string(b))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return os.WriteFile(path, formatted, 0o644)
+}
+
+// insertBefore inserts line above the marker comment, with the same indent.
+func insertBefore(src, marker, line string) (string, error) {
+	i := strings.Index(src, marker+"\n")
+	if i < 0 {
+		return "", fmt.Errorf("marker %q not found", marker)
+	}
+	lineStart := strings.LastIndex(src[:i], "\n") + 1
+	indent := src[lineStart:i]
+	return src[:lineStart] + indent + line + src[lineStart:], nil
+}
+
+// addImport adds path to the import group holding the other app packages.
+func addImport(src, path string) (string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+	if err != nil {
+		return "", err
+	}
+	var last int
+	for _, spec := range f.Imports {
+		if strings.HasPrefix(spec.Path.Value, `"boilerplate/internal/app/`) {
+			last = int(spec.End()) - 1
+		}
+	}
+	if last == 0 {
+		return "", errors.New("no boilerplate/internal/app import to add to")
+	}
+	return src[:last] + "\n\t" + path + src[last:], nil
+}
+
+func pascal(snake string) string {
+	return strings.ReplaceAll(pascalWords(snake), " ", "")
+}
+
+// pascalWords turns sales_order into "Sales Order", keeping ID as initialism.
+func pascalWords(snake string) string {
+	words := strings.Split(snake, "_")
+	for i, w := range words {
+		switch w {
+		case "":
+			continue
+		case "id", "url", "ip":
+			words[i] = strings.ToUpper(w)
+		default:
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
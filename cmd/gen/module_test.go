@@ -0,0 +1,123 @@
+package main
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewModule(t *testing.T) {
+	m, err := newModule("Sales-Order", "number:string, amount:decimal,paid_date:date")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{m.Name, m.Pascal, m.Camel, m.Package, m.Table, m.Route, m.Title}
+	want := []string{"sales_order", "SalesOrder", "salesOrder", "salesorder", "m_sales_order", "/sales-order", "Sales Order"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("names = %q, want %q", got, want)
+			break
+		}
+	}
+	if len(m.Fields) != 3 || m.Fields[2].Pascal != "PaidDate" || !m.HasTime() {
+		t.Errorf("fields = %+v", m.Fields)
+	}
+
+	for _, tt := range []struct{ name, fields string }{
+		{"1order", "number:string"},
+		{"order", ""},
+		{"order", "number"},
+		{"order", "number:uuid"},
+		{"order", "tenant_id:string"},
+		{"order", "number:string,number:int"},
+		{"order", "Number-1:string"},
+	} {
+		if _, err := newModule(tt.name, tt.fields); err == nil {
+			t.Errorf("newModule(%q, %q) error = nil", tt.name, tt.fields)
+		}
+	}
+}
+
+func TestModule_render(t *testing.T) {
+	var fields []string
+	for typ := range fieldTypes {
+		fields = append(fields, "f_"+typ+":"+typ)
+	}
+	m, err := newModule("sales_order", strings.Join(fields, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := m.render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 8 {
+		t.Errorf("render() = %d files, want 8", len(files))
+	}
+	for _, f := range files {
+		if !strings.HasSuffix(f.path, ".go") {
+			if !strings.Contains(string(f.content), m.Table) {
+				t.Errorf("%s doesn't mention %s", f.path, m.Table)
+			}
+			continue
+		}
+		formatted, err := format.Source(f.content)
+		if err != nil {
+			t.Errorf("%s: %v", f.path, err)
+			continue
+		}
+		if string(formatted) != string(f.content) {
+			t.Errorf("%s isn't gofmt'ed", f.path)
+		}
+	}
+}
+
+func TestModule_register(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{filepath.Join("internal", "factory", "factory.go"), filepath.Join("internal", "delivery", "http.go")} {
+		src, err := os.ReadFile(filepath.Join("..", "..", path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(root, path), src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := newModule("sales_order", "number:string")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// twice, the second run finds the module wired
+	for i := 0; i < 2; i++ {
+		if err = m.register(root); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for path, lines := range map[string][]string{
+		filepath.Join("internal", "factory", "factory.go"): {
+			"SalesOrderRepository repository.SalesOrder",
+			"f.SalesOrderRepository = repository.NewSalesOrder(f.DB)",
+		},
+		filepath.Join("internal", "delivery", "http.go"): {
+			`"boilerplate/internal/app/salesorder"`,
+			`salesorder.NewHandler(f).Route(e.Group("/sales-order"))`,
+		},
+	} {
+		src, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range lines {
+			if n := strings.Count(string(src), line); n != 1 {
+				t.Errorf("%s has %q %d times, want once", path, line, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package abstraction
+
+// ReadConsistency decides whether reads made outside a transaction may be
+// served by a replica, so that callers read their own writes.
+type ReadConsistency interface {
+	// Stale reports whether the caller of c wrote recently enough that
+	// replicas may not have its writes yet.
+	Stale(c *Context) bool
+	// MarkWrite records that the caller of c wrote to the primary.
+	MarkWrite(c *Context)
+	// ReplicaAvailable reports whether a replica can serve reads.
+	ReplicaAvailable() bool
+}
+
+var readConsistency ReadConsistency
+
+// SetReadConsistency enables replica reads. Without it every query goes to
+// the primary.
+func SetReadConsistency(rc ReadConsistency) {
+	readConsistency = rc
+}
+
+// UseReplica reports whether reads of c outside a transaction may go to a
+// replica. The caller's own recent writes pin it to the primary for the rest
+// of the request.
+func (c *Context) UseReplica() bool {
+	if readConsistency == nil || !readConsistency.ReplicaAvailable() {
+		return false
+	}
+	if c.readPrimary == nil {
+		stale := readConsistency.Stale(c)
+		c.readPrimary = &stale
+	}
+	return !*c.readPrimary
+}
+
+// MarkWrite pins the reads of c, and of the caller's next requests within the
+// read-your-writes window, to the primary.
+func (c *Context) MarkWrite() {
+	readPrimary := true
+	c.readPrimary = &readPrimary
+	if readConsistency != nil {
+		readConsistency.MarkWrite(c)
+	}
+}
@@ -1,21 +1,105 @@
 package abstraction
 
 import (
+	"context"
+
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
+type contextKey struct{}
+
 type Context struct {
 	echo.Context
 	Auth *AuthContext
 	Trx  *TrxContext
+
+	// TenantID is the tenant the request acts on, queries on tenant owned
+	// tables are scoped to it.
+	TenantID string
+
+	// readPrimary caches whether reads go to the primary, see UseReplica.
+	readPrimary *bool
 }
 
 type AuthContext struct {
-	ID     int
-	RoleID int
+	ID       int
+	RoleID   int
+	GroupIDs []int
+
+	// OrgUnitID is the org unit of the caller, Global callers are not
+	// restricted to its subtree.
+	OrgUnitID *int
+	Global    bool
 }
 
 type TrxContext struct {
 	Db *gorm.DB
+
+	// Depth counts the savepoints opened by nested transactions.
+	Depth int
+
+	// OnCommit and OnRollback hold the hooks registered with AfterCommit
+	// and AfterRollback.
+	OnCommit   []func()
+	OnRollback []func()
+}
+
+// InGroup reports whether the caller is a member of any of the groups.
+func (a *AuthContext) InGroup(ids ...int) bool {
+	if a == nil {
+		return false
+	}
+	for _, id := range ids {
+		for _, groupID := range a.GroupIDs {
+			if id == groupID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AfterCommit registers fn to run once the current transaction commits. It
+// runs right away outside a transaction and is dropped when the transaction
+// or the savepoint it was registered in rolls back.
+func (c *Context) AfterCommit(fn func()) {
+	if c.Trx == nil {
+		fn()
+		return
+	}
+	c.Trx.OnCommit = append(c.Trx.OnCommit, fn)
+}
+
+// AfterRollback registers fn to run if the current transaction, or the
+// savepoint it was registered in, rolls back. It does nothing outside a
+// transaction.
+func (c *Context) AfterRollback(fn func()) {
+	if c.Trx == nil {
+		return
+	}
+	c.Trx.OnRollback = append(c.Trx.OnRollback, fn)
+}
+
+// RequestContext returns the request context carrying c, so code that only
+// sees a context.Context (e.g. GORM callbacks) can reach the caller.
+func (c *Context) RequestContext() context.Context {
+	return context.WithValue(c.Request().Context(), contextKey{}, c)
+}
+
+// RequestID returns the X-Request-ID assigned to the current request.
+func (c *Context) RequestID() string {
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
+
+// FromContext returns the *Context stored by RequestContext, if any.
+func FromContext(ctx context.Context) (*Context, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	c, ok := ctx.Value(contextKey{}).(*Context)
+	return c, ok
 }
@@ -0,0 +1,119 @@
+package abstraction
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Filter is a DTO filter narrowing list queries.
+type Filter interface {
+	Apply(db *gorm.DB) *gorm.DB
+}
+
+// CRUD is the repository interface implemented by CRUDRepository.
+type CRUD[T any, F Filter] interface {
+	Find(ctx *Context, f *F, p *Pagination, fs *Fieldset) ([]*T, *PaginationInfo, error)
+	FindByID(ctx *Context, id int) (*T, error)
+	FindByIDForUpdate(ctx *Context, id int) (*T, error)
+	Create(ctx *Context, e *T) *gorm.DB
+	Update(ctx *Context, e *T) *gorm.DB
+	Delete(ctx *Context, id int) *gorm.DB
+}
+
+// CRUDRepository implements the common queries of the model T filtered by F.
+// Embed it in a repository and add or override methods for custom behaviour.
+type CRUDRepository[T any, F Filter] struct {
+	Repository
+
+	// UpdateColumns are the columns written by Update, all columns are saved
+	// when empty.
+	UpdateColumns []string
+}
+
+// Find returns a page of rows matching f, counted with COUNT(*) in offset mode
+// and not counted at all in cursor mode.
+func (r *CRUDRepository[T, F]) Find(ctx *Context, f *F, p *Pagination, fs *Fieldset) ([]*T, *PaginationInfo, error) {
+	var (
+		data  []*T
+		count int64
+		err   error
+
+		info   = &PaginationInfo{Pagination: p}
+		filter = func(db *gorm.DB) *gorm.DB {
+			if f != nil {
+				return (*f).Apply(db)
+			}
+			return db
+		}
+	)
+
+	if p != nil {
+		if err = p.ParseSort(r.SortFields); err != nil {
+			return nil, nil, err
+		}
+	}
+	if fs != nil {
+		if err = fs.ParseFieldset(r.Fields, r.Expansions); err != nil {
+			return nil, nil, err
+		}
+		if p.IsCursor() {
+			fs.Require(p.SortColumns()...)
+		}
+	}
+
+	if p.IsCursor() {
+		if err = r.CheckTrx(ctx).Model(new(T)).Scopes(filter, fs.Apply, p.ApplyCursor).Find(&data).Error; err != nil {
+			return nil, nil, err
+		}
+		if info, err = p.SetCursorInfo(r.Db, &data); err != nil {
+			return nil, nil, err
+		}
+		return data, info, nil
+	}
+
+	if err = r.CheckTrx(ctx).Model(new(T)).Scopes(filter).Count(&count).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if err = r.CheckTrx(ctx).Model(new(T)).Scopes(filter, fs.Apply, func(db *gorm.DB) *gorm.DB {
+		if p != nil {
+			if p.Page == nil || p.PageSize == nil {
+				p.Init()
+			}
+			return db.Offset(p.GetOffset()).Limit(p.GetLimit()).Order(p.GetOrderBy())
+		}
+		return db
+	}).Find(&data).Error; err != nil {
+		return nil, nil, err
+	}
+
+	info.Count = count
+	return data, info, nil
+}
+
+func (r *CRUDRepository[T, F]) FindByID(ctx *Context, id int) (data *T, err error) {
+	err = r.CheckTrx(ctx).Where("id = ?", id).Take(&data).Error
+	return
+}
+
+// FindByIDForUpdate is FindByID locking the row until the end of the
+// transaction of ctx, for rows read before they are written.
+func (r *CRUDRepository[T, F]) FindByIDForUpdate(ctx *Context, id int) (data *T, err error) {
+	err = r.CheckTrx(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&data).Error
+	return
+}
+
+func (r *CRUDRepository[T, F]) Create(ctx *Context, e *T) *gorm.DB {
+	return r.CheckTrx(ctx).Create(e)
+}
+
+func (r *CRUDRepository[T, F]) Update(ctx *Context, e *T) *gorm.DB {
+	if len(r.UpdateColumns) == 0 {
+		return r.CheckTrx(ctx).Save(e)
+	}
+	return r.CheckTrx(ctx).Model(e).Select(r.UpdateColumns).Updates(e)
+}
+
+func (r *CRUDRepository[T, F]) Delete(ctx *Context, id int) *gorm.DB {
+	return r.CheckTrx(ctx).Where("id = ?", id).Delete(new(T))
+}
@@ -0,0 +1,146 @@
+package abstraction
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type crudRow struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Note string `json:"note"`
+}
+
+type crudFilter struct {
+	Name []string
+}
+
+func (f crudFilter) Apply(db *gorm.DB) *gorm.DB {
+	if f.Name != nil {
+		db.Where("name IN (?)", f.Name)
+	}
+	return db
+}
+
+func crudRepository(t *testing.T) (*CRUDRepository[crudRow, crudFilter], *Context) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&crudRow{}); err != nil {
+		t.Fatal(err)
+	}
+	r := &CRUDRepository[crudRow, crudFilter]{
+		Repository: Repository{
+			Db:         db,
+			SortFields: SortFields{"id": "id", "name": "name"},
+			Fields:     FieldMap{"id": "id", "name": "name"},
+		},
+		UpdateColumns: []string{"name"},
+	}
+	ctx := tenantContext("")
+	for _, name := range []string{"budi", "ani", "citra", "dewi"} {
+		if err = r.Create(ctx, &crudRow{Name: name, Note: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r, ctx
+}
+
+func crudNames(rows []*crudRow) []string {
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = row.Name
+	}
+	return names
+}
+
+func TestCRUDRepository_Find(t *testing.T) {
+	r, ctx := crudRepository(t)
+
+	sort := "name"
+	p := (&Pagination{Sort: &sort}).SetPage(1).SetPageSize(2)
+	data, info, err := r.Find(ctx, &crudFilter{Name: []string{"ani", "budi", "citra"}}, p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// one row more than the page tells the service more rows exist
+	if got, want := crudNames(data), []string{"ani", "budi", "citra"}; !slices.Equal(got, want) {
+		t.Errorf("Find() = %v, want %v", got, want)
+	}
+	if info.Count != 3 {
+		t.Errorf("Count = %d, want 3", info.Count)
+	}
+
+	fields := "name"
+	if data, _, err = r.Find(ctx, nil, (&Pagination{Sort: &sort}).SetPageSize(1), &Fieldset{Fields: &fields}); err != nil {
+		t.Fatal(err)
+	}
+	if data[0].Name != "ani" || data[0].Note != "" {
+		t.Errorf("Find() with fields = %+v, want the name and id only", data[0])
+	}
+
+	bad := "note"
+	var fieldsetErr *FieldsetError
+	if _, _, err = r.Find(ctx, nil, nil, &Fieldset{Fields: &bad}); !errors.As(err, &fieldsetErr) {
+		t.Errorf("Find() with an unknown field error = %v, want a FieldsetError", err)
+	}
+}
+
+func TestCRUDRepository_FindCursor(t *testing.T) {
+	r, ctx := crudRepository(t)
+
+	var (
+		sort = "name"
+		got  []string
+		p    = &Pagination{Sort: &sort, Cursor: new(string)}
+	)
+	for page := 0; page < 3; page++ {
+		data, info, err := r.Find(ctx, nil, p.SetPageSize(3), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Count != 0 {
+			t.Errorf("Count = %d, cursor pages aren't counted", info.Count)
+		}
+		got = append(got, crudNames(data)...)
+		if info.NextCursor == nil {
+			break
+		}
+		p = &Pagination{Sort: &sort, Cursor: info.NextCursor}
+	}
+	if want := []string{"ani", "budi", "citra", "dewi"}; !slices.Equal(got, want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+}
+
+func TestCRUDRepository_write(t *testing.T) {
+	r, ctx := crudRepository(t)
+
+	data, err := r.FindByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.Name, data.Note = "budi santoso", "changed"
+	if err = r.Update(ctx, data).Error; err != nil {
+		t.Fatal(err)
+	}
+	if data, err = r.FindByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if data.Name != "budi santoso" || data.Note != "budi" {
+		t.Errorf("Update() = %+v, want the UpdateColumns written only", data)
+	}
+
+	if result := r.Delete(ctx, 1); result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("Delete() = %d rows, %v", result.RowsAffected, result.Error)
+	}
+	if _, err = r.FindByID(ctx, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindByID() after Delete() error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,235 @@
+package abstraction
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the decoded form of the opaque cursor handed to clients. Values
+// holds the order-by columns of the boundary row, ending with its ID.
+type cursor struct {
+	Values    []interface{} `json:"v"`
+	Direction string        `json:"d"`
+}
+
+type orderColumn struct {
+	Column string
+	Desc   bool
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := new(cursor)
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(c); err != nil || (c.Direction != cursorNext && c.Direction != cursorPrev) {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// IsCursor reports whether the client asked for keyset pagination.
+func (p *Pagination) IsCursor() bool {
+	return p != nil && p.Cursor != nil
+}
+
+// orderColumns returns the columns resolved by ParseSort, always ending with
+// id so that every row has a unique position.
+func (p *Pagination) orderColumns() []orderColumn {
+	if len(p.orders) == 0 {
+		return []orderColumn{{Column: "id", Desc: true}}
+	}
+	columns := append([]orderColumn{}, p.orders...)
+	for _, col := range columns {
+		if col.Column == "id" {
+			return columns
+		}
+	}
+	return append(columns, orderColumn{Column: "id", Desc: columns[len(columns)-1].Desc})
+}
+
+// ApplyCursor restricts db to the rows following (or preceding) the requested
+// cursor and orders them for keyset pagination. It fetches one row more than
+// the page size so that SetCursorInfo can tell whether more rows exist.
+func (p *Pagination) ApplyCursor(db *gorm.DB) *gorm.DB {
+	p.Init()
+
+	var (
+		c        *cursor
+		err      error
+		columns  = p.orderColumns()
+		backward bool
+	)
+	if p.Cursor != nil && *p.Cursor != "" {
+		if c, err = decodeCursor(*p.Cursor); err != nil || len(c.Values) != len(columns) {
+			_ = db.AddError(ErrInvalidCursor)
+			return db
+		}
+		backward = c.Direction == cursorPrev
+	}
+
+	nullable := nullableColumns(db, columns)
+	if c != nil {
+		var or []clause.Expression
+		for i, col := range columns {
+			and := make([]clause.Expression, 0, i+1)
+			for j := 0; j < i; j++ {
+				// IS NULL for a nil value
+				and = append(and, clause.Eq{Column: clause.Column{Name: columns[j].Column}, Value: c.Values[j]})
+			}
+			cond, ok := after(clause.Column{Name: col.Column}, c.Values[i], col.Desc != backward, nullable[col.Column])
+			if !ok {
+				continue
+			}
+			or = append(or, clause.And(append(and, cond)...))
+		}
+		db.Where(clause.Or(or...))
+	}
+
+	for _, col := range columns {
+		desc := col.Desc != backward
+		if nullable[col.Column] {
+			// NULLs sort after every value whatever the dialect, see after
+			db.Order(clause.OrderByColumn{Column: clause.Column{Name: col.Column + " IS NULL", Raw: true}, Desc: desc})
+		}
+		db.Order(clause.OrderByColumn{Column: clause.Column{Name: col.Column}, Desc: desc})
+	}
+	return db.Limit(p.GetLimit())
+}
+
+// after returns the condition of the values of column sorted after value,
+// i.e. below it when desc. NULLs sort after every value: in descending order
+// they come first and no value is above a NULL. ok is false when no value
+// comes after value.
+func after(column clause.Column, value interface{}, desc, nullable bool) (expr clause.Expression, ok bool) {
+	switch {
+	case !nullable && desc:
+		return clause.Lt{Column: column, Value: value}, true
+	case !nullable:
+		return clause.Gt{Column: column, Value: value}, true
+	case value == nil && desc:
+		return clause.Neq{Column: column, Value: nil}, true
+	case value == nil:
+		return nil, false
+	case desc:
+		return clause.Lt{Column: column, Value: value}, true
+	default:
+		return clause.Or(clause.Gt{Column: column, Value: value}, clause.Eq{Column: column, Value: nil}), true
+	}
+}
+
+// nullableColumns returns the columns of the model of db that may hold NULL,
+// i.e. whose field is a pointer. Without a model no column is nullable.
+func nullableColumns(db *gorm.DB, columns []orderColumn) map[string]bool {
+	nullable := make(map[string]bool)
+	if db.Statement.Model == nil || db.Statement.Parse(db.Statement.Model) != nil {
+		return nullable
+	}
+	for _, col := range columns {
+		name := col.Column
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if field := db.Statement.Schema.LookUpField(name); field != nil && field.FieldType.Kind() == reflect.Ptr {
+			nullable[col.Column] = true
+		}
+	}
+	return nullable
+}
+
+// SetCursorInfo trims the look-ahead row from data, which must be a pointer
+// to the slice filled by a query scoped with ApplyCursor, restores the
+// requested order for backward pages and builds the next/prev cursors.
+func (p *Pagination) SetCursorInfo(db *gorm.DB, data interface{}) (*PaginationInfo, error) {
+	info := &PaginationInfo{Pagination: p}
+
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return nil, errors.New("data must be a pointer to a slice")
+	}
+	v = v.Elem()
+
+	var (
+		c   *cursor
+		err error
+	)
+	if p.Cursor != nil && *p.Cursor != "" {
+		if c, err = decodeCursor(*p.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	backward := c != nil && c.Direction == cursorPrev
+
+	more := false
+	if v.Len() > *p.PageSize {
+		more = true
+		v.Set(v.Slice(0, *p.PageSize))
+	}
+	if backward {
+		swap := reflect.Swapper(v.Interface())
+		for i, j := 0, v.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	if v.Len() == 0 {
+		return info, nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(v.Index(0).Interface()); err != nil {
+		return nil, err
+	}
+	position := func(row reflect.Value, direction string) (*string, error) {
+		row = reflect.Indirect(row)
+		c := cursor{Direction: direction}
+		for _, col := range p.orderColumns() {
+			name := col.Column
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = name[i+1:]
+			}
+			field := stmt.Schema.LookUpField(name)
+			if field == nil {
+				return nil, ErrInvalidCursor
+			}
+			value, _ := field.ValueOf(db.Statement.Context, row)
+			c.Values = append(c.Values, value)
+		}
+		s := encodeCursor(c)
+		return &s, nil
+	}
+
+	if more || backward {
+		if info.NextCursor, err = position(v.Index(v.Len()-1), cursorNext); err != nil {
+			return nil, err
+		}
+	}
+	if c != nil && (more || !backward) {
+		if info.PrevCursor, err = position(v.Index(0), cursorPrev); err != nil {
+			return nil, err
+		}
+	}
+	info.MoreRecords = info.NextCursor != nil
+	return info, nil
+}
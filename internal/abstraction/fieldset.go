@@ -0,0 +1,199 @@
+package abstraction
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// FieldMap maps the field names accepted from clients to database columns.
+// The names must match the JSON keys of the returned model.
+type FieldMap map[string]string
+
+// Expansion is a relation clients may load with expand. Columns lists the
+// columns the relation needs selected, e.g. its foreign key.
+type Expansion struct {
+	Relation string
+	Columns  []string
+}
+
+// Expansions maps the expand names accepted from clients to relations. The
+// names must match the JSON keys of the relations on the returned model.
+type Expansions map[string]Expansion
+
+// FieldsetError is returned by ParseFieldset for unknown fields or relations.
+type FieldsetError struct {
+	Param   string
+	Value   string
+	Allowed []string
+}
+
+func (e *FieldsetError) Error() string {
+	return fmt.Sprintf("invalid %s %q, allowed values: %s", e.Param, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// Fieldset ...
+// swagger:params Fieldset
+type Fieldset struct {
+	// Fields comma separated fields to return.
+	// Required: false
+	// In: query
+	Fields *string `query:"fields" json:"fields,omitempty" example:"id,name,email"`
+
+	// Expand comma separated relations to load.
+	// Required: false
+	// In: query
+	Expand *string `query:"expand" json:"expand,omitempty" example:"created_by_user"`
+
+	columns  []string
+	keys     []string
+	preloads []string
+}
+
+// ParseFieldset validates fs against the fields and relations declared by a
+// repository and resolves them to columns and associations.
+func (fs *Fieldset) ParseFieldset(fields FieldMap, expansions Expansions) error {
+	fs.columns, fs.keys, fs.preloads = nil, nil, nil
+
+	for _, name := range splitList(fs.Expand) {
+		expansion, ok := expansions[name]
+		if !ok {
+			names := make([]string, 0, len(expansions))
+			for n := range expansions {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return &FieldsetError{Param: "expand", Value: name, Allowed: names}
+		}
+		fs.preloads = append(fs.preloads, expansion.Relation)
+		fs.keys = append(fs.keys, name)
+		fs.Require(expansion.Columns...)
+	}
+
+	names := splitList(fs.Fields)
+	if len(names) == 0 {
+		fs.columns, fs.keys = nil, nil
+		return nil
+	}
+	for _, name := range names {
+		column, ok := fields[name]
+		if !ok {
+			allowed := make([]string, 0, len(fields))
+			for n := range fields {
+				allowed = append(allowed, n)
+			}
+			sort.Strings(allowed)
+			return &FieldsetError{Param: "fields", Value: name, Allowed: allowed}
+		}
+		fs.keys = append(fs.keys, name)
+		fs.Require(column)
+	}
+	fs.Require("id")
+	return nil
+}
+
+// Require adds columns to the SELECT without returning them to the client,
+// e.g. the columns a keyset cursor is built from. It has no effect when all
+// fields are returned.
+func (fs *Fieldset) Require(columns ...string) {
+	if fs.Fields == nil || strings.TrimSpace(*fs.Fields) == "" {
+		return
+	}
+	for _, column := range columns {
+		found := false
+		for _, c := range fs.columns {
+			if c == column {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fs.columns = append(fs.columns, column)
+		}
+	}
+}
+
+// Columns returns the columns to select, or nil to select all of them.
+func (fs *Fieldset) Columns() []string {
+	if fs == nil {
+		return nil
+	}
+	return fs.columns
+}
+
+// Apply narrows the SELECT and preloads the requested relations.
+func (fs *Fieldset) Apply(db *gorm.DB) *gorm.DB {
+	if fs == nil {
+		return db
+	}
+	if len(fs.columns) > 0 {
+		db.Select(fs.columns)
+	}
+	return fs.Preload(db)
+}
+
+// Preload loads the requested relations only.
+func (fs *Fieldset) Preload(db *gorm.DB) *gorm.DB {
+	if fs == nil {
+		return db
+	}
+	for _, relation := range fs.preloads {
+		db.Preload(relation)
+	}
+	return db
+}
+
+// Project drops the fields the client did not ask for from data, which may
+// be a model or a slice of models. data is returned untouched when all
+// fields were asked for.
+func (fs *Fieldset) Project(data interface{}) (interface{}, error) {
+	if fs == nil || len(fs.columns) == 0 {
+		return data, nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err = json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	project := func(item interface{}) interface{} {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return item
+		}
+		projected := make(map[string]interface{}, len(fs.keys))
+		for _, key := range fs.keys {
+			if value, ok := m[key]; ok {
+				projected[key] = value
+			}
+		}
+		return projected
+	}
+
+	if items, ok := v.([]interface{}); ok {
+		for i := range items {
+			items[i] = project(items[i])
+		}
+		return items, nil
+	}
+	return project(v), nil
+}
+
+func splitList(s *string) (items []string) {
+	if s == nil {
+		return nil
+	}
+	for _, item := range strings.Split(*s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return
+}
@@ -0,0 +1,328 @@
+package abstraction
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"boilerplate/pkg/database/dialect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operator is a filter operator used as a query parameter suffix,
+// e.g. created_date[gte]=2024-01-01.
+type Operator string
+
+const (
+	OpEq    Operator = "eq"
+	OpNe    Operator = "ne"
+	OpGt    Operator = "gt"
+	OpGte   Operator = "gte"
+	OpLt    Operator = "lt"
+	OpLte   Operator = "lte"
+	OpIn    Operator = "in"
+	OpNin   Operator = "nin"
+	OpLike  Operator = "like"
+	OpIlike Operator = "ilike"
+	OpNull  Operator = "null"
+)
+
+var (
+	conditionKey   = regexp.MustCompile(`^([a-z0-9_]+)\[([a-z]+)\]$`)
+	conditionGroup = regexp.MustCompile(`^or\.([a-z0-9_]+)\.([a-z0-9_]+)(?:\[([a-z]+)\])?$`)
+	likeEscaper    = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// Condition is a single parsed filter.
+type Condition struct {
+	Field    string
+	Column   string
+	Operator Operator
+	Value    interface{}
+}
+
+// Conditions holds the filters parsed by ParseConditions. And conditions
+// must all match; within each Or group at least one must match.
+type Conditions struct {
+	And []Condition
+	Or  map[string][]Condition
+}
+
+// ConditionSetter is implemented by DTO filters so that generic handlers can
+// hand them the conditions parsed by ParseConditions.
+type ConditionSetter interface {
+	SetConditions(c Conditions)
+}
+
+// FilterError is returned by ParseConditions for unknown fields, operators
+// or malformed values.
+type FilterError struct {
+	Param   string
+	Message string
+	Allowed []string
+}
+
+func (e *FilterError) Error() string {
+	if len(e.Allowed) > 0 {
+		return fmt.Sprintf("invalid filter %q: %s, allowed values: %s", e.Param, e.Message, strings.Join(e.Allowed, ", "))
+	}
+	return fmt.Sprintf("invalid filter %q: %s", e.Param, e.Message)
+}
+
+type filterField struct {
+	column    string
+	operators []Operator
+	typ       reflect.Type
+	bound     bool
+}
+
+// ParseConditions reads operator filters from values for the fields of
+// filter, a DTO filter struct. A field opts in with an `ops` tag listing its
+// operators and may set `column` when the column differs from its name. The
+// field name comes from the `query` tag, or from `json` for fields echo does
+// not bind (query:"-").
+//
+// Accepted forms are field[op]=value and or.<group>.field[op]=value; the
+// conditions sharing a group are ORed together. A plain field=value is read
+// as eq only for fields echo does not bind, the others keep their own
+// handling in the DTO. in and nin take comma separated values and null takes
+// true or false.
+func ParseConditions(values url.Values, filter interface{}) (c Conditions, err error) {
+	fields := filterFields(reflect.TypeOf(filter))
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var (
+			group, name string
+			op          = OpEq
+		)
+		if m := conditionGroup.FindStringSubmatch(key); m != nil {
+			group, name = m[1], m[2]
+			if m[3] != "" {
+				op = Operator(m[3])
+			}
+		} else if m := conditionKey.FindStringSubmatch(key); m != nil {
+			name, op = m[1], Operator(m[2])
+		} else if field, ok := fields[key]; ok && !field.bound {
+			name = key
+		} else {
+			continue
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return c, &FilterError{Param: key, Message: "unknown field", Allowed: fieldNames(fields)}
+		}
+		if !hasOperator(field.operators, op) {
+			allowed := make([]string, 0, len(field.operators))
+			for _, o := range field.operators {
+				allowed = append(allowed, string(o))
+			}
+			return c, &FilterError{Param: key, Message: "unsupported operator", Allowed: allowed}
+		}
+
+		for _, raw := range values[key] {
+			var value interface{}
+			if value, err = field.parse(op, raw); err != nil {
+				return c, &FilterError{Param: key, Message: err.Error()}
+			}
+			condition := Condition{Field: name, Column: field.column, Operator: op, Value: value}
+			if group == "" {
+				c.And = append(c.And, condition)
+				continue
+			}
+			if c.Or == nil {
+				c.Or = make(map[string][]Condition)
+			}
+			c.Or[group] = append(c.Or[group], condition)
+		}
+	}
+	return c, nil
+}
+
+// Apply adds the conditions to db as parameterised WHERE clauses.
+func (c Conditions) Apply(db *gorm.DB) *gorm.DB {
+	for _, condition := range c.And {
+		db.Where(condition.Expression())
+	}
+
+	groups := make([]string, 0, len(c.Or))
+	for group := range c.Or {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		exprs := make([]clause.Expression, 0, len(c.Or[group]))
+		for _, condition := range c.Or[group] {
+			exprs = append(exprs, condition.Expression())
+		}
+		db.Where(clause.Or(exprs...))
+	}
+	return db
+}
+
+// Expression ...
+func (c Condition) Expression() clause.Expression {
+	column := clause.Column{Name: c.Column}
+	switch c.Operator {
+	case OpNe:
+		return clause.Neq{Column: column, Value: c.Value}
+	case OpGt:
+		return clause.Gt{Column: column, Value: c.Value}
+	case OpGte:
+		return clause.Gte{Column: column, Value: c.Value}
+	case OpLt:
+		return clause.Lt{Column: column, Value: c.Value}
+	case OpLte:
+		return clause.Lte{Column: column, Value: c.Value}
+	case OpIn:
+		return clause.IN{Column: column, Values: c.Value.([]interface{})}
+	case OpNin:
+		return clause.Not(clause.IN{Column: column, Values: c.Value.([]interface{})})
+	case OpLike:
+		return dialect.Like{Column: column, Value: c.Value}
+	case OpIlike:
+		return dialect.ILike{Column: column, Value: c.Value}
+	case OpNull:
+		if c.Value.(bool) {
+			return clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}}
+		}
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{column}}
+	default:
+		return clause.Eq{Column: column, Value: c.Value}
+	}
+}
+
+func filterFields(t reflect.Type) map[string]filterField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fields := make(map[string]filterField)
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ops := f.Tag.Get("ops")
+		if ops == "" {
+			continue
+		}
+
+		name, bound := strings.Split(f.Tag.Get("query"), ",")[0], true
+		if name == "-" || name == "" {
+			name, bound = strings.Split(f.Tag.Get("json"), ",")[0], false
+		}
+
+		field := filterField{column: f.Tag.Get("column"), typ: f.Type, bound: bound}
+		if field.column == "" {
+			field.column = name
+		}
+		for field.typ.Kind() == reflect.Ptr || field.typ.Kind() == reflect.Slice {
+			field.typ = field.typ.Elem()
+		}
+		for _, op := range strings.Split(ops, ",") {
+			field.operators = append(field.operators, Operator(strings.TrimSpace(op)))
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func fieldNames(fields map[string]filterField) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func hasOperator(operators []Operator, op Operator) bool {
+	for _, o := range operators {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (f filterField) parse(op Operator, raw string) (interface{}, error) {
+	switch op {
+	case OpIn, OpNin:
+		var values []interface{}
+		for _, item := range strings.Split(raw, ",") {
+			v, err := f.convert(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case OpLike, OpIlike:
+		if f.typ.Kind() != reflect.String {
+			return nil, fmt.Errorf("%s needs a text field", op)
+		}
+		return "%" + likeEscaper.Replace(raw) + "%", nil
+	case OpNull:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("should be true or false")
+		}
+		return v, nil
+	default:
+		return f.convert(raw)
+	}
+}
+
+func (f filterField) convert(raw string) (interface{}, error) {
+	if f.typ == timeType {
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("should be a date (2006-01-02) or RFC3339 time")
+	}
+	switch f.typ.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("should be true or false")
+		}
+		return v, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("should be a number")
+		}
+		return v, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("should be a positive number")
+		}
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("should be a number")
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported field type %s", f.typ)
+}
@@ -0,0 +1,106 @@
+package abstraction
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type conditionFilter struct {
+	ID          []int      `json:"id" query:"id" ops:"eq,in,gte"`
+	Name        []string   `json:"name" query:"name" column:"m_user.name" ops:"eq,ilike"`
+	Email       []string   `json:"email" query:"email" ops:"ilike"`
+	IsActive    *bool      `json:"is_active" query:"is_active" ops:"eq,ne"`
+	CreatedDate *time.Time `json:"created_date" query:"-" ops:"eq,gte,lt,null"`
+	Search      *string    `json:"search" query:"search"`
+}
+
+type conditionRow struct {
+	ID int
+}
+
+func TestParseConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantVars int
+		wantErr  bool
+	}{
+		{
+			name:    "plain params of bound fields are left to the dto",
+			query:   "name=budi&search=budi&page=1",
+			wantSQL: `SELECT * FROM "condition_rows"`,
+		},
+		{
+			name:     "operators",
+			query:    "id[in]=1,2&name[ilike]=bu_di&is_active[ne]=false&created_date[gte]=2024-01-01",
+			wantSQL:  `SELECT * FROM "condition_rows" WHERE "created_date" >= $1 AND "id" IN ($2,$3) AND "is_active" <> $4 AND "m_user"."name" ILIKE $5`,
+			wantVars: 5,
+		},
+		{
+			name:     "or group",
+			query:    "or.q.name[ilike]=budi&or.q.email[ilike]=budi&id[gte]=10",
+			wantSQL:  `SELECT * FROM "condition_rows" WHERE "id" >= $1 AND ("email" ILIKE $2 OR "m_user"."name" ILIKE $3)`,
+			wantVars: 3,
+		},
+		{
+			name:     "plain param of unbound field is eq",
+			query:    "created_date=2024-01-01",
+			wantSQL:  `SELECT * FROM "condition_rows" WHERE "created_date" = $1`,
+			wantVars: 1,
+		},
+		{
+			name:    "null operator",
+			query:   "created_date[null]=true",
+			wantSQL: `SELECT * FROM "condition_rows" WHERE "created_date" IS NULL`,
+		},
+		{
+			name:    "unknown field",
+			query:   "password[eq]=secret",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported operator",
+			query:   "email[eq]=a@b.c",
+			wantErr: true,
+		},
+		{
+			name:    "malformed value",
+			query:   "id[in]=1,abc",
+			wantErr: true,
+		},
+		{
+			name:    "malformed date",
+			query:   "created_date[lt]=yesterday",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			c, err := ParseConditions(values, &conditionFilter{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConditions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var filterErr *FilterError
+				if !errors.As(err, &filterErr) {
+					t.Errorf("ParseConditions() error = %v, want *FilterError", err)
+				}
+				return
+			}
+
+			var data []conditionRow
+			stmt := dryRunDB(t).Model(&conditionRow{}).Scopes(c.Apply).Find(&data)
+			if got := strings.TrimSpace(stmt.Statement.SQL.String()); got != tt.wantSQL {
+				t.Errorf("Apply() sql = %s, want %s", got, tt.wantSQL)
+			}
+			if got := len(stmt.Statement.Vars); got != tt.wantVars {
+				t.Errorf("Apply() vars = %d, want %d", got, tt.wantVars)
+			}
+		})
+	}
+}
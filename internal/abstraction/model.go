@@ -31,3 +31,8 @@ func (m *Entity) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// Auditable is implemented by models whose changes are recorded in the audit log.
+type Auditable interface {
+	AuditEntity() string
+}
@@ -0,0 +1,22 @@
+package abstraction
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// OrgUnitScope restricts a query to rows whose column holds an org unit in the
+// caller's subtree. Requests without auth (e.g. login or background jobs) and
+// global callers are not restricted, callers without an org unit see nothing.
+func OrgUnitScope(ctx *Context, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if ctx == nil || ctx.Auth == nil || ctx.Auth.Global {
+			return db
+		}
+		if ctx.Auth.OrgUnitID == nil {
+			return db.Where("1 = 0")
+		}
+		return db.Where(fmt.Sprintf("%s IN (SELECT descendant_id FROM m_org_unit_closure WHERE ancestor_id = ?)", column), *ctx.Auth.OrgUnitID)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
 
 	"gorm.io/gorm"
@@ -13,9 +14,11 @@ import (
 
 type PaginationInfo struct {
 	*Pagination
-	Pages       int   `json:"pages"`
-	Count       int64 `json:"count"`
-	MoreRecords bool  `json:"more_records" example:"false"`
+	Pages       int     `json:"pages"`
+	Count       int64   `json:"count"`
+	MoreRecords bool    `json:"more_records" example:"false"`
+	NextCursor  *string `json:"next_cursor,omitempty"`
+	PrevCursor  *string `json:"prev_cursor,omitempty"`
 }
 
 // Pagination ...
@@ -42,7 +45,21 @@ type Pagination struct {
 	// Enum: asc, desc
 	Order *string `query:"order" json:"order,omitempty" example:"desc"`
 
-	once sync.Once
+	// Cursor opaque position returned as next_cursor or prev_cursor.
+	// Sending it, even empty for the first page, switches to keyset
+	// pagination which skips the total count.
+	// Required: false
+	// In: query
+	Cursor *string `query:"cursor" json:"cursor,omitempty"`
+
+	// Sort comma separated fields to order the results by, prefix a field
+	// with - for descending order. Takes precedence over order_by and order.
+	// Required: false
+	// In: query
+	Sort *string `query:"sort" json:"sort,omitempty" example:"-created_date,name"`
+
+	once   sync.Once
+	orders []orderColumn
 }
 
 func (p *Pagination) SetPageInfo(count int64, lenData int) (info *PaginationInfo) {
@@ -77,18 +94,18 @@ func (p *Pagination) SetPageSize(pageSize int) *Pagination {
 	return p
 }
 
+// GetOrderBy returns the ORDER BY expression resolved by ParseSort. Only
+// whitelisted columns end up in it; without ParseSort it is "id desc".
 func (p *Pagination) GetOrderBy() string {
-	orderBy := "id"
-	if p.OrderBy != nil {
-		orderBy = *p.OrderBy
-	}
-
-	order := "desc"
-	if p.Order != nil {
-		order = *p.Order
+	var orders []string
+	for _, col := range p.orderColumns() {
+		order := "asc"
+		if col.Desc {
+			order = "desc"
+		}
+		orders = append(orders, fmt.Sprintf("%s %s", col.Column, order))
 	}
-
-	return fmt.Sprintf("%s %s", orderBy, order)
+	return strings.Join(orders, ", ")
 }
 
 // NewPagination ...
@@ -163,10 +180,7 @@ func (p *Pagination) Apply(db *gorm.DB) *gorm.DB {
 		p.Init()
 		db.Offset(p.GetOffset()).Limit(p.GetLimit())
 	}
-	if p.OrderBy != nil {
-		db.Order(p.GetOrderBy())
-	}
-	return db
+	return db.Order(p.GetOrderBy())
 }
 
 func (p *Pagination) Params(params url.Values) {
@@ -182,4 +196,10 @@ func (p *Pagination) Params(params url.Values) {
 	if p.Order != nil {
 		params.Add("order", *p.Order)
 	}
+	if p.Cursor != nil {
+		params.Add("cursor", *p.Cursor)
+	}
+	if p.Sort != nil {
+		params.Add("sort", *p.Sort)
+	}
 }
@@ -0,0 +1,318 @@
+package abstraction
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type cursorRow struct {
+	ID   int
+	Name string
+}
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestPagination_ApplyCursor(t *testing.T) {
+	sortByName := "name"
+	tests := []struct {
+		name     string
+		p        *Pagination
+		wantSQL  string
+		wantVars int
+		wantErr  bool
+	}{
+		{
+			name:     "first page",
+			p:        &Pagination{Cursor: new(string)},
+			wantSQL:  `SELECT * FROM "cursor_rows" ORDER BY "id" DESC LIMIT $1`,
+			wantVars: 1,
+		},
+		{
+			name: "next page by name",
+			p: &Pagination{
+				Sort: &sortByName,
+				Cursor: func() *string {
+					s := encodeCursor(cursor{Values: []interface{}{"budi", 3}, Direction: cursorNext})
+					return &s
+				}(),
+			},
+			wantSQL:  `SELECT * FROM "cursor_rows" WHERE ("name" > $1 OR ("name" = $2 AND "id" > $3)) ORDER BY "name","id" LIMIT $4`,
+			wantVars: 4,
+		},
+		{
+			name: "previous page",
+			p: &Pagination{
+				Cursor: func() *string { s := encodeCursor(cursor{Values: []interface{}{3}, Direction: cursorPrev}); return &s }(),
+			},
+			wantSQL:  `SELECT * FROM "cursor_rows" WHERE "id" > $1 ORDER BY "id" LIMIT $2`,
+			wantVars: 2,
+		},
+		{
+			name:    "malformed cursor",
+			p:       &Pagination{Cursor: func() *string { s := "%%%"; return &s }()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.ParseSort(SortFields{"id": "id", "name": "name"}); err != nil {
+				t.Fatalf("ParseSort() error = %v", err)
+			}
+			var data []cursorRow
+			stmt := dryRunDB(t).Model(&cursorRow{}).Scopes(tt.p.ApplyCursor).Find(&data)
+			if (stmt.Error != nil) != tt.wantErr {
+				t.Fatalf("ApplyCursor() error = %v, wantErr %v", stmt.Error, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := strings.TrimSpace(stmt.Statement.SQL.String()); got != tt.wantSQL {
+				t.Errorf("ApplyCursor() sql = %s, want %s", got, tt.wantSQL)
+			}
+			if got := len(stmt.Statement.Vars); got != tt.wantVars {
+				t.Errorf("ApplyCursor() vars = %d, want %d", got, tt.wantVars)
+			}
+		})
+	}
+}
+
+type cursorNullRow struct {
+	ID   int
+	Rank *int
+}
+
+// TestPagination_cursorNullable pages through a nullable column both ways,
+// NULLs must neither end the pages early nor be skipped.
+func TestPagination_cursorNullable(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&cursorNullRow{}); err != nil {
+		t.Fatal(err)
+	}
+	rank := func(i int) *int { return &i }
+	rows := []*cursorNullRow{
+		{ID: 1, Rank: rank(2)}, {ID: 2}, {ID: 3, Rank: rank(1)}, {ID: 4},
+		{ID: 5, Rank: rank(2)}, {ID: 6}, {ID: 7, Rank: rank(3)},
+	}
+	if err = db.Create(rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	page := func(sort, c string) ([]int, *PaginationInfo) {
+		pageSize := 2
+		p := &Pagination{Sort: &sort, Cursor: &c, PageSize: &pageSize}
+		if err := p.ParseSort(SortFields{"id": "id", "rank": "rank"}); err != nil {
+			t.Fatal(err)
+		}
+		var data []*cursorNullRow
+		if err := db.Model(&cursorNullRow{}).Scopes(p.ApplyCursor).Find(&data).Error; err != nil {
+			t.Fatal(err)
+		}
+		info, err := p.SetCursorInfo(db, &data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, row := range data {
+			ids = append(ids, row.ID)
+		}
+		return ids, info
+	}
+
+	tests := []struct {
+		sort string
+		want []int
+	}{
+		{sort: "rank", want: []int{3, 1, 5, 7, 2, 4, 6}},
+		{sort: "-rank", want: []int{6, 4, 2, 7, 5, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			var (
+				got    []int
+				pages  [][]int
+				cursor string
+			)
+			for {
+				ids, info := page(tt.sort, cursor)
+				got, pages = append(got, ids...), append(pages, ids)
+				if info.NextCursor == nil {
+					break
+				}
+				cursor = *info.NextCursor
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("pages = %v, want %v", got, tt.want)
+			}
+
+			// back from the last page
+			_, info := page(tt.sort, cursor)
+			for i := len(pages) - 2; i >= 0; i-- {
+				var ids []int
+				ids, info = page(tt.sort, *info.PrevCursor)
+				if !reflect.DeepEqual(ids, pages[i]) {
+					t.Errorf("previous page = %v, want %v", ids, pages[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPagination_SetCursorInfo(t *testing.T) {
+	pageSize := 2
+	prev := encodeCursor(cursor{Values: []interface{}{10}, Direction: cursorPrev})
+	next := encodeCursor(cursor{Values: []interface{}{10}, Direction: cursorNext})
+	tests := []struct {
+		name     string
+		cursor   string
+		rows     []*cursorRow
+		wantIDs  []int
+		wantNext bool
+		wantPrev bool
+	}{
+		{
+			name:     "first page with more rows",
+			rows:     []*cursorRow{{ID: 9}, {ID: 8}, {ID: 7}},
+			wantIDs:  []int{9, 8},
+			wantNext: true,
+		},
+		{
+			name:     "last page",
+			cursor:   next,
+			rows:     []*cursorRow{{ID: 9}},
+			wantIDs:  []int{9},
+			wantPrev: true,
+		},
+		{
+			name:     "backward page is returned in requested order",
+			cursor:   prev,
+			rows:     []*cursorRow{{ID: 11}, {ID: 12}, {ID: 13}},
+			wantIDs:  []int{12, 11},
+			wantNext: true,
+			wantPrev: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.cursor
+			p := &Pagination{PageSize: &pageSize, Cursor: &c}
+			p.Init()
+
+			info, err := p.SetCursorInfo(dryRunDB(t), &tt.rows)
+			if err != nil {
+				t.Fatalf("SetCursorInfo() error = %v", err)
+			}
+			var ids []int
+			for _, row := range tt.rows {
+				ids = append(ids, row.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("SetCursorInfo() rows = %v, want %v", ids, tt.wantIDs)
+			}
+			if (info.NextCursor != nil) != tt.wantNext {
+				t.Errorf("SetCursorInfo() next = %v, want %v", info.NextCursor != nil, tt.wantNext)
+			}
+			if (info.PrevCursor != nil) != tt.wantPrev {
+				t.Errorf("SetCursorInfo() prev = %v, want %v", info.PrevCursor != nil, tt.wantPrev)
+			}
+		})
+	}
+}
+
+func TestPagination_ParseSort(t *testing.T) {
+	fields := SortFields{"id": "id", "name": "m_user.name", "created_date": "m_user.created_date"}
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name    string
+		p       *Pagination
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			p:    &Pagination{},
+			want: "id desc",
+		},
+		{
+			name: "multiple columns",
+			p:    &Pagination{Sort: str("-created_date,name")},
+			want: "m_user.created_date desc, m_user.name asc, id asc",
+		},
+		{
+			name: "order_by and order",
+			p:    &Pagination{OrderBy: str("name"), Order: str("ASC")},
+			want: "m_user.name asc, id asc",
+		},
+		{
+			name:    "unknown field",
+			p:       &Pagination{Sort: str("password")},
+			wantErr: true,
+		},
+		{
+			name:    "injected order_by",
+			p:       &Pagination{OrderBy: str("id; DROP TABLE m_user")},
+			wantErr: true,
+		},
+		{
+			name:    "unknown direction",
+			p:       &Pagination{OrderBy: str("id"), Order: str("sideways")},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate field",
+			p:       &Pagination{Sort: str("name,-name")},
+			wantErr: true,
+		},
+		{
+			name: "plus sign",
+			p:    &Pagination{Sort: str("+name")},
+			want: "m_user.name asc, id asc",
+		},
+		{
+			name:    "several signs",
+			p:       &Pagination{Sort: str("--name")},
+			wantErr: true,
+		},
+		{
+			name:    "mixed signs",
+			p:       &Pagination{Sort: str("+-name")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.ParseSort(fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var sortErr *SortError
+				if !errors.As(err, &sortErr) || len(sortErr.Allowed) == 0 {
+					t.Errorf("ParseSort() error = %v, want *SortError with allowed values", err)
+				}
+				return
+			}
+			if got := tt.p.GetOrderBy(); got != tt.want {
+				t.Errorf("GetOrderBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,19 +6,45 @@ import (
 	"strings"
 	"time"
 
+	"boilerplate/pkg/database/dialect"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/plugin/dbresolver"
 )
 
 type Repository struct {
-	Db *gorm.DB
+	Db         *gorm.DB
+	SortFields SortFields
+	Fields     FieldMap
+	Expansions Expansions
+
+	// OrgUnitColumn opts the repository in to org unit scoping, every query
+	// made through CheckTrx is restricted to rows whose column holds an org unit
+	// in the caller's subtree.
+	OrgUnitColumn string
 }
 
 func (r *Repository) CheckTrx(ctx *Context) *gorm.DB {
+	db := r.CheckTrxUnscoped(ctx)
+	if r.OrgUnitColumn != "" {
+		db = db.Scopes(OrgUnitScope(ctx, r.OrgUnitColumn))
+	}
+	return db
+}
+
+// CheckTrxUnscoped is CheckTrx without the org unit scope, for lookups that
+// must see every row such as uniqueness checks. Both stay scoped to the tenant
+// of ctx, see RegisterTenant. Outside a transaction reads go to a replica
+// unless ctx.UseReplica says otherwise, writes always go to the primary.
+func (r *Repository) CheckTrxUnscoped(ctx *Context) *gorm.DB {
 	if ctx.Trx != nil {
-		return ctx.Trx.Db.WithContext(ctx.Request().Context()).Clauses(dbresolver.Write)
+		return ctx.Trx.Db.WithContext(ctx.RequestContext()).Clauses(dbresolver.Write)
+	}
+	if ctx.UseReplica() {
+		return r.Db.WithContext(ctx.RequestContext())
 	}
-	return r.Db.WithContext(ctx.Request().Context()).Clauses(dbresolver.Write)
+	return r.Db.WithContext(ctx.RequestContext()).Clauses(dbresolver.Write)
 }
 
 func (r *Repository) Filter(ctx *Context, query *gorm.DB, payload interface{}) *gorm.DB {
@@ -44,7 +70,7 @@ func (r *Repository) Filter(ctx *Context, query *gorm.DB, payload interface{}) *
 				case "LIKE":
 					query = query.Where(fmt.Sprintf("%s LIKE ?", key), "%"+val.String()+"%")
 				case "ILIKE":
-					query = query.Where(fmt.Sprintf("%s ILIKE ?", key), "%"+val.String()+"%")
+					query = query.Where(dialect.ILike{Column: clause.Column{Name: key}, Value: "%" + val.String() + "%"})
 				case "DATE":
 					tmpDate, err := time.Parse("2006-01-02", val.String())
 					if err != nil {
@@ -92,7 +118,7 @@ func (r *Repository) FilterWithTableName(ctx *Context, query *gorm.DB, payload i
 				case "LIKE":
 					query = query.Where(fmt.Sprintf("%s.%s LIKE ?", tableName, key), "%"+val.String()+"%")
 				case "ILIKE":
-					query = query.Where(fmt.Sprintf("%s.%s ILIKE ?", tableName, key), "%"+val.String()+"%")
+					query = query.Where(dialect.ILike{Column: clause.Column{Table: tableName, Name: key}, Value: "%" + val.String() + "%"})
 				case "DATE":
 					tmpDate, err := time.Parse("2006-01-02", val.String())
 					if err != nil {
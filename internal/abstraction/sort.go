@@ -0,0 +1,97 @@
+package abstraction
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortFields maps the sort names accepted from clients to database columns.
+// Repositories declare it so that only known columns ever reach ORDER BY.
+type SortFields map[string]string
+
+// Names returns the accepted sort names in alphabetical order.
+func (f SortFields) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SortError is returned by ParseSort for unknown fields or directions.
+type SortError struct {
+	Param   string
+	Value   string
+	Allowed []string
+}
+
+func (e *SortError) Error() string {
+	return fmt.Sprintf("invalid %s %q, allowed values: %s", e.Param, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// ParseSort validates the requested ordering against fields and resolves it
+// to columns. The sort parameter takes a comma separated list of names, each
+// optionally prefixed with - for descending or + for ascending order, e.g.
+// sort=-created_date,name. Without sort, the order_by and order parameters
+// are honoured, and without either the results are ordered by id desc.
+func (p *Pagination) ParseSort(fields SortFields) error {
+	p.orders = nil
+
+	if p.Sort != nil && strings.TrimSpace(*p.Sort) != "" {
+		seen := make(map[string]bool)
+		for _, item := range strings.Split(*p.Sort, ",") {
+			item = strings.TrimSpace(item)
+			// a single sign, --name isn't a field
+			name, desc := strings.CutPrefix(item, "-")
+			if !desc {
+				name, _ = strings.CutPrefix(item, "+")
+			}
+
+			column, ok := fields[name]
+			if !ok || seen[name] {
+				return &SortError{Param: "sort", Value: item, Allowed: fields.Names()}
+			}
+			seen[name] = true
+			p.orders = append(p.orders, orderColumn{Column: column, Desc: desc})
+		}
+		return nil
+	}
+
+	if p.OrderBy == nil && p.Order == nil {
+		return nil
+	}
+
+	column := "id"
+	if p.OrderBy != nil && *p.OrderBy != "" {
+		var ok bool
+		if column, ok = fields[*p.OrderBy]; !ok {
+			return &SortError{Param: "order_by", Value: *p.OrderBy, Allowed: fields.Names()}
+		}
+	}
+
+	desc := true
+	if p.Order != nil && *p.Order != "" {
+		switch strings.ToLower(*p.Order) {
+		case "asc":
+			desc = false
+		case "desc":
+		default:
+			return &SortError{Param: "order", Value: *p.Order, Allowed: []string{"asc", "desc"}}
+		}
+	}
+
+	p.orders = []orderColumn{{Column: column, Desc: desc}}
+	return nil
+}
+
+// SortColumns returns the columns the results are ordered by, including the
+// id tiebreaker.
+func (p *Pagination) SortColumns() []string {
+	var columns []string
+	for _, col := range p.orderColumns() {
+		columns = append(columns, col.Column)
+	}
+	return columns
+}
@@ -0,0 +1,181 @@
+package abstraction
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	tenantColumn  = "tenant_id"
+	skipTenantKey = "tenant:skip"
+)
+
+var (
+	// ErrTenantRequired is returned for queries on tenant owned tables made
+	// by a request that doesn't name a tenant.
+	ErrTenantRequired = errors.New("tenant: the request doesn't name a tenant")
+	// ErrCrossTenant is returned when a request writes a row of another tenant.
+	ErrCrossTenant = errors.New("tenant: the row belongs to another tenant")
+
+	tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+)
+
+// Tenant is embedded by models owned by a tenant. The column is only written
+// on create, rows never move between tenants.
+type Tenant struct {
+	TenantID string `json:"tenant_id" gorm:"<-:create" example:"default"`
+}
+
+// ValidTenantID reports whether id can name a tenant. Tenant ids end up in
+// Redis keys and object names, so they are restricted to lowercase letters,
+// digits and dashes.
+func ValidTenantID(id string) bool {
+	return tenantPattern.MatchString(id)
+}
+
+// WithoutTenant lifts the tenant scope off db, for the few lookups that must
+// see every tenant.
+func WithoutTenant(db *gorm.DB) *gorm.DB {
+	return db.Set(skipTenantKey, true)
+}
+
+// RegisterTenant adds the callbacks scoping tables with a tenant_id column to
+// the tenant of the request carried by the statement context (see
+// Context.RequestContext): reads, updates and deletes are filtered on it and
+// created rows are stamped with it. Queries without a request context, such as
+// background workers, and raw SQL are not scoped.
+func RegisterTenant(db *gorm.DB) error {
+	cb := db.Callback()
+	if cb.Query().Get("tenant:scope") != nil {
+		return nil
+	}
+	if err := cb.Create().Before("gorm:create").Register("tenant:stamp", stampTenant); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tenant:scope", scopeTenant); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant:scope", scopeTenant); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:scope", scopeTenantWrite); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register("tenant:scope", scopeTenantWrite)
+}
+
+// requestTenant returns the tenant column of the statement's model and the
+// tenant of its request, ok is false when the statement isn't scoped.
+func requestTenant(tx *gorm.DB) (field *schema.Field, tenantID string, ok bool) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return nil, "", false
+	}
+	if field = tx.Statement.Schema.LookUpField(tenantColumn); field == nil || field.DBName == "" {
+		return nil, "", false
+	}
+	if skip, _ := tx.Get(skipTenantKey); skip == true {
+		return nil, "", false
+	}
+	c, found := FromContext(tx.Statement.Context)
+	if !found {
+		return nil, "", false
+	}
+	if c.TenantID == "" {
+		_ = tx.AddError(ErrTenantRequired)
+		return nil, "", false
+	}
+	return field, c.TenantID, true
+}
+
+func tenantCondition(field *schema.Field, tenantID string) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID}
+}
+
+func scopeTenant(tx *gorm.DB) {
+	if field, tenantID, ok := requestTenant(tx); ok {
+		tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{tenantCondition(field, tenantID)}})
+	}
+}
+
+// scopeTenantWrite scopes updates and deletes that already have conditions,
+// the tenant condition alone must not turn a missing WHERE into an update of
+// every row of the tenant.
+func scopeTenantWrite(tx *gorm.DB) {
+	if !hasConditions(tx.Statement) {
+		return
+	}
+	scopeTenant(tx)
+}
+
+func hasConditions(stmt *gorm.Statement) bool {
+	if _, ok := stmt.Clauses["WHERE"]; ok || stmt.AllowGlobalUpdate {
+		return true
+	}
+	if stmt.Schema == nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return false
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Struct:
+		_, zero := pk.ValueOf(stmt.Context, stmt.ReflectValue)
+		return !zero
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			if _, zero := pk.ValueOf(stmt.Context, reflect.Indirect(stmt.ReflectValue.Index(i))); !zero {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stampTenant sets the tenant of created rows, refusing rows set to another
+// tenant, and keeps upserts from taking over rows of other tenants.
+func stampTenant(tx *gorm.DB) {
+	field, tenantID, ok := requestTenant(tx)
+	if !ok {
+		return
+	}
+
+	stmt := tx.Statement
+	stamp := func(rv reflect.Value) {
+		v, zero := field.ValueOf(stmt.Context, rv)
+		if zero {
+			if err := field.Set(stmt.Context, rv, tenantID); err != nil {
+				_ = tx.AddError(err)
+			}
+			return
+		}
+		if v != tenantID {
+			_ = tx.AddError(ErrCrossTenant)
+		}
+	}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Struct:
+		stamp(stmt.ReflectValue)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			stamp(reflect.Indirect(stmt.ReflectValue.Index(i)))
+		}
+	case reflect.Map:
+		if m, ok := stmt.Dest.(map[string]interface{}); ok {
+			if v, exists := m[field.DBName]; !exists {
+				m[field.DBName] = tenantID
+			} else if v != tenantID {
+				_ = tx.AddError(ErrCrossTenant)
+			}
+		}
+	}
+
+	if c, ok := stmt.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs, tenantCondition(field, tenantID))
+			stmt.AddClause(onConflict)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package abstraction
+
+import (
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+type tenantRow struct {
+	ID   int
+	Name string
+	Tenant
+}
+
+// tenantDB returns a database scoped by RegisterTenant holding the rows 1 and
+// 2 of tenant a and 3 of tenant b.
+func tenantDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = RegisterTenant(db); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&tenantRow{}); err != nil {
+		t.Fatal(err)
+	}
+	rows := []*tenantRow{
+		{ID: 1, Name: "a1", Tenant: Tenant{TenantID: "a"}},
+		{ID: 2, Name: "a2", Tenant: Tenant{TenantID: "a"}},
+		{ID: 3, Name: "b1", Tenant: Tenant{TenantID: "b"}},
+	}
+	if err = db.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func tenantContext(tenantID string) *Context {
+	return &Context{
+		Context:  echo.New().NewContext(httptest.NewRequest("GET", "/", nil), httptest.NewRecorder()),
+		TenantID: tenantID,
+	}
+}
+
+func names(t *testing.T, db *gorm.DB) []string {
+	var got []string
+	if err := db.Model(&tenantRow{}).Order("id").Pluck("name", &got).Error; err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestRegisterTenant_scope(t *testing.T) {
+	db := tenantDB(t)
+	a := db.WithContext(tenantContext("a").RequestContext())
+
+	var rows []*tenantRow
+	if err := a.Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Name != "a1" || rows[1].Name != "a2" {
+		t.Errorf("Find() = %+v, want the rows of tenant a", rows)
+	}
+
+	var count int64
+	if err := a.Model(&tenantRow{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("Count() = %d, %v, want 2", count, err)
+	}
+	if err := a.Take(&tenantRow{}, 3).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Take() of a row of tenant b error = %v, want not found", err)
+	}
+	if got := names(t, a.Scopes(WithoutTenant)); len(got) != 3 {
+		t.Errorf("WithoutTenant sees %v, want every row", got)
+	}
+	if got := names(t, db); len(got) != 3 {
+		t.Errorf("without a request sees %v, want every row", got)
+	}
+
+	err := db.WithContext(tenantContext("").RequestContext()).Find(&rows).Error
+	if !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("Find() without a tenant error = %v, want %v", err, ErrTenantRequired)
+	}
+}
+
+func TestRegisterTenant_stamp(t *testing.T) {
+	db := tenantDB(t)
+	a := db.WithContext(tenantContext("a").RequestContext())
+
+	row := &tenantRow{ID: 4, Name: "a3"}
+	if err := a.Create(row).Error; err != nil {
+		t.Fatal(err)
+	}
+	if row.TenantID != "a" {
+		t.Errorf("Create() stamped %q, want a", row.TenantID)
+	}
+
+	rows := []*tenantRow{{ID: 5, Name: "a4"}, {ID: 6, Name: "b2", Tenant: Tenant{TenantID: "b"}}}
+	if err := a.Create(&rows).Error; !errors.Is(err, ErrCrossTenant) {
+		t.Errorf("Create() of a row of tenant b error = %v, want %v", err, ErrCrossTenant)
+	}
+	if err := a.Model(&tenantRow{}).Create(map[string]interface{}{"id": 7, "name": "b3", "tenant_id": "b"}).Error; !errors.Is(err, ErrCrossTenant) {
+		t.Errorf("Create() of a map of tenant b error = %v, want %v", err, ErrCrossTenant)
+	}
+	if got := names(t, db); len(got) != 4 {
+		t.Errorf("rows = %v, want the cross tenant rows refused", got)
+	}
+}
+
+func TestRegisterTenant_upsert(t *testing.T) {
+	db := tenantDB(t)
+	a := db.WithContext(tenantContext("a").RequestContext())
+
+	rows := []*tenantRow{{ID: 1, Name: "a1 updated"}, {ID: 3, Name: "b1 taken over"}}
+	if err := a.Clauses(clause.OnConflict{UpdateAll: true}).Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := names(t, db)
+	if want := []string{"a1 updated", "a2", "b1"}; !slices.Equal(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterTenant_write(t *testing.T) {
+	db := tenantDB(t)
+	a := db.WithContext(tenantContext("a").RequestContext())
+
+	// the tenant condition doesn't stand in for a missing WHERE
+	if err := a.Model(&tenantRow{}).Update("name", "x").Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("Update() without conditions error = %v, want %v", err, gorm.ErrMissingWhereClause)
+	}
+	if err := a.Delete(&tenantRow{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("Delete() without conditions error = %v, want %v", err, gorm.ErrMissingWhereClause)
+	}
+
+	if result := a.Model(&tenantRow{}).Where("name LIKE ?", "%1").Update("name", "x"); result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("Update() = %d rows, %v, want the row of tenant a", result.RowsAffected, result.Error)
+	}
+	if result := a.Delete(&tenantRow{ID: 3}); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("Delete() of a row of tenant b = %d rows, %v, want none", result.RowsAffected, result.Error)
+	}
+	if err := a.Session(&gorm.Session{AllowGlobalUpdate: true}).Model(&tenantRow{}).Update("name", "y").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(t, db), []string{"y", "y", "b1"}; !slices.Equal(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+}
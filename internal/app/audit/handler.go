@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/factory"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/util/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+// Find Audit Log
+// @Summary Find audit log of an entity
+// @Description Find the recorded changes of a single entity, newest first, for global users only
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param entity path string true "entity name, e.g. user"
+// @Param id path string true "entity id"
+// @Param request query dto.AuditFilter true "request query"
+// @param request query abstraction.Pagination true "request query pagination"
+// @Success 200 {object} dto.FindAuditResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 403 {object} response.ErrorResponse403
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /audit/{entity}/{id} [get]
+func (h *handler) Find(c echo.Context) (err error) {
+	f := new(dto.AuditFilter)
+	if err = c.Bind(f); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+	if err = c.Validate(f); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err).Send(c)
+	}
+	if f.Conditions, err = abstraction.ParseConditions(c.QueryParams(), f); err != nil {
+		return response.ErrorQuery(err).Send(c)
+	}
+
+	p := new(abstraction.Pagination)
+	if err = c.Bind(p); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+
+	var (
+		data []*model.AuditLogEntityModel
+		info *abstraction.PaginationInfo
+	)
+	if data, info, err = h.service.Find(c.(*abstraction.Context), f, p); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+
+	return response.SuccessResponse(data).WithPagination(info).Send(c)
+}
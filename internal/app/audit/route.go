@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"boilerplate/internal/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Route ...
+func (h *handler) Route(v *echo.Group) {
+	v.GET("/:entity/:id", h.Find, middleware.Authentication)
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"errors"
+	"math"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/factory"
+	"boilerplate/internal/model"
+	"boilerplate/internal/repository"
+	"boilerplate/pkg/util/response"
+)
+
+type Service interface {
+	Find(ctx *abstraction.Context, f *dto.AuditFilter, p *abstraction.Pagination) ([]*model.AuditLogEntityModel, *abstraction.PaginationInfo, error)
+}
+
+type service struct {
+	AuditRepository repository.Audit
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		AuditRepository: f.AuditRepository,
+	}
+}
+
+// Find is restricted to global callers, the log holds the values of the rows
+// before and after each change.
+func (s *service) Find(ctx *abstraction.Context, f *dto.AuditFilter, p *abstraction.Pagination) (data []*model.AuditLogEntityModel, info *abstraction.PaginationInfo, err error) {
+	if ctx.Auth == nil || !ctx.Auth.Global {
+		return nil, nil, response.ErrorBuilder(&response.ErrorConstant.Forbidden, errors.New("only global users can read the audit log"))
+	}
+	if data, info, err = s.AuditRepository.Find(ctx, f, p); err != nil {
+		if errQuery := response.ErrorQuery(err); errQuery != nil {
+			return nil, nil, errQuery
+		}
+		return nil, nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	if p != nil && p.PageSize != nil {
+		info.Pages = int(math.Ceil(float64(info.Count) / float64(*p.PageSize)))
+		if len(data) > *p.PageSize {
+			data = data[:len(data)-1]
+			info.MoreRecords = true
+		}
+	}
+	return
+}
@@ -60,12 +60,12 @@ func (s *service) Login(ctx *abstraction.Context, payload *dto.AuthLoginRequest)
 		return nil, response.ErrorBuilder(&response.ErrorConstant.Unauthorized, errors.New("password is incorrect"))
 	}
 
-	authLoggedInUser, err := redis.Client().Get(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_flag", data.ID)).Result()
+	authLoggedInUser, err := redis.Client().Get(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_flag", data.ID)).Result()
 	if err != nil && !errors.Is(err, goRedis.Nil) {
 		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 	}
 	if authLoggedInUser == "1" {
-		authLoggedInUserInfo, err := redis.Client().HGetAll(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", data.ID)).Result()
+		authLoggedInUserInfo, err := redis.Client().HGetAll(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_info", data.ID)).Result()
 		if err != nil && !errors.Is(err, goRedis.Nil) {
 			return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 		}
@@ -83,9 +83,10 @@ func (s *service) Login(ctx *abstraction.Context, payload *dto.AuthLoginRequest)
 	}
 
 	accessTokenClaims := &modeltoken.AccessTokenClaims{
-		ID:     encryptedUserID,
-		RoleID: encryptedRoleID,
-		Exp:    time.Now().Add(config.JWT().AccessTokenExpiry).Unix(),
+		ID:       encryptedUserID,
+		RoleID:   encryptedRoleID,
+		TenantID: data.TenantID,
+		Exp:      time.Now().Add(config.JWT().AccessTokenExpiry).Unix(),
 	}
 	authToken := modeltoken.NewAuthToken(accessTokenClaims)
 	accessToken, err := authToken.AccessToken()
@@ -97,13 +98,13 @@ func (s *service) Login(ctx *abstraction.Context, payload *dto.AuthLoginRequest)
 		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 	}
 
-	_ = redis.Client().Set(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_flag", data.ID), true, config.JWT().AccessTokenExpiry)
-	_ = redis.Client().HSet(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", data.ID), map[string]interface{}{
+	_ = redis.Client().Set(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_flag", data.ID), true, config.JWT().AccessTokenExpiry)
+	_ = redis.Client().HSet(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_info", data.ID), map[string]interface{}{
 		"ip_address": ctx.RealIP(),
 		"user_agent": ctx.Request().UserAgent(),
 		"token":      accessToken,
 	})
-	_ = redis.Client().Expire(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", data.ID), config.JWT().AccessTokenExpiry)
+	_ = redis.Client().Expire(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_info", data.ID), config.JWT().AccessTokenExpiry)
 
 	return &dto.AuthLoginResponse{
 		AccessToken:     accessToken,
@@ -135,9 +136,12 @@ func (s *service) RefreshToken(ctx *abstraction.Context, payload *dto.RefreshTok
 		return nil, response.CustomErrorBuilder(http.StatusBadRequest, err.Error(), "invalid_refresh_token")
 	}
 
-	if refreshTokenAuthCtx.ID != accessTokenAuthCtx.ID || refreshTokenAuthCtx.RoleID != accessTokenAuthCtx.RoleID {
+	if refreshTokenAuthCtx.ID != accessTokenAuthCtx.ID || refreshTokenAuthCtx.RoleID != accessTokenAuthCtx.RoleID || refreshTokenClaims.TenantID != accessTokenClaims.TenantID {
 		return nil, response.CustomErrorBuilder(http.StatusUnauthorized, "unauthorized_to_refresh_token", "unauthorized_to_refresh_token")
 	}
+	if refreshTokenClaims.TenantID != "" {
+		ctx.TenantID = refreshTokenClaims.TenantID
+	}
 
 	accessTokenClaims = refreshTokenClaims.AccessTokenClaims()
 	authToken := modeltoken.NewAuthToken(accessTokenClaims)
@@ -150,15 +154,15 @@ func (s *service) RefreshToken(ctx *abstraction.Context, payload *dto.RefreshTok
 		return nil, response.CustomErrorBuilder(http.StatusUnauthorized, err.Error(), "err_generate_refresh_token")
 	}
 
-	_ = redis.Client().Del(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_flag", accessTokenAuthCtx.ID))
-	_ = redis.Client().Del(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", accessTokenAuthCtx.ID))
-	_ = redis.Client().Set(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_flag", accessTokenAuthCtx.ID), true, config.JWT().AccessTokenExpiry)
-	_ = redis.Client().HSet(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", accessTokenAuthCtx.ID), map[string]interface{}{
+	_ = redis.Client().Del(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_flag", accessTokenAuthCtx.ID))
+	_ = redis.Client().Del(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_info", accessTokenAuthCtx.ID))
+	_ = redis.Client().Set(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_flag", accessTokenAuthCtx.ID), true, config.JWT().AccessTokenExpiry)
+	_ = redis.Client().HSet(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_info", accessTokenAuthCtx.ID), map[string]interface{}{
 		"ip_address": ctx.RealIP(),
 		"user_agent": ctx.Request().UserAgent(),
 		"token":      accessToken,
 	})
-	_ = redis.Client().Expire(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", accessTokenAuthCtx.ID), config.JWT().AccessTokenExpiry)
+	_ = redis.Client().Expire(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_info", accessTokenAuthCtx.ID), config.JWT().AccessTokenExpiry)
 
 	return &dto.RefreshTokenResponse{
 		AccessToken:  accessToken,
@@ -174,7 +178,7 @@ func (s *service) encryptTokenClaims(v int) (encryptedString string, err error)
 func (s *service) Logout(ctx *abstraction.Context) (map[string]interface{}, error) {
 	tokenString := strings.Replace(ctx.Request().Header.Get("Authorization"), "Bearer ", "", -1)
 
-	result, err := redis.Client().HGetAll(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", ctx.Auth.ID)).Result()
+	result, err := redis.Client().HGetAll(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_info", ctx.Auth.ID)).Result()
 	if err != nil && !errors.Is(err, goRedis.Nil) {
 		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 	}
@@ -185,8 +189,8 @@ func (s *service) Logout(ctx *abstraction.Context) (map[string]interface{}, erro
 		}, nil
 	}
 
-	_ = redis.Client().Del(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_flag", ctx.Auth.ID))
-	_ = redis.Client().Del(ctx.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", ctx.Auth.ID))
+	_ = redis.Client().Del(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_flag", ctx.Auth.ID))
+	_ = redis.Client().Del(ctx.Request().Context(), redis.Key(ctx, "auth_user_id_%d_info", ctx.Auth.ID))
 
 	return map[string]interface{}{
 		"message": "Logout successful",
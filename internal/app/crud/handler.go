@@ -0,0 +1,127 @@
+package crud
+
+import (
+	"errors"
+	"strconv"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/pkg/util/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler serves the CRUD endpoints of a Service. Modules wrap its methods in
+// their own handler so that each endpoint keeps its swag annotations.
+type Handler[T any, F abstraction.Filter, C any, U any] struct {
+	Service Service[T, F, C, U]
+}
+
+func NewHandler[T any, F abstraction.Filter, C any, U any](s Service[T, F, C, U]) *Handler[T, F, C, U] {
+	return &Handler[T, F, C, U]{
+		Service: s,
+	}
+}
+
+func (h *Handler[T, F, C, U]) Find(c echo.Context) (err error) {
+	f := new(F)
+	if err = c.Bind(f); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+	var conditions abstraction.Conditions
+	if conditions, err = abstraction.ParseConditions(c.QueryParams(), f); err != nil {
+		return response.ErrorQuery(err).Send(c)
+	}
+	if setter, ok := any(f).(abstraction.ConditionSetter); ok {
+		setter.SetConditions(conditions)
+	}
+
+	p := new(abstraction.Pagination)
+	if err = c.Bind(p); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+
+	fs := new(abstraction.Fieldset)
+	if err = c.Bind(fs); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+
+	var (
+		data []*T
+		info *abstraction.PaginationInfo
+	)
+	if data, info, err = h.Service.Find(c.(*abstraction.Context), f, p, fs); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+
+	projected, err := fs.Project(data)
+	if err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+
+	return response.SuccessResponse(projected).WithPagination(info).Send(c)
+}
+
+func (h *Handler[T, F, C, U]) FindByID(c echo.Context) (err error) {
+	var id int
+	if id, err = paramID(c); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err, err.Error()).Send(c)
+	}
+	var data *T
+	if data, err = h.Service.FindByID(c.(*abstraction.Context), id); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
+
+func (h *Handler[T, F, C, U]) Create(c echo.Context) (err error) {
+	payload := new(C)
+	if err = c.Bind(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err = c.Validate(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err).Send(c)
+	}
+	var data *T
+	if data, err = h.Service.Create(c.(*abstraction.Context), payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
+
+func (h *Handler[T, F, C, U]) Update(c echo.Context) (err error) {
+	var id int
+	if id, err = paramID(c); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err, err.Error()).Send(c)
+	}
+	payload := new(U)
+	if err = c.Bind(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err = c.Validate(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err).Send(c)
+	}
+	var data *T
+	if data, err = h.Service.Update(c.(*abstraction.Context), id, payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
+
+func (h *Handler[T, F, C, U]) Delete(c echo.Context) (err error) {
+	var id int
+	if id, err = paramID(c); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err, err.Error()).Send(c)
+	}
+	if err = h.Service.Delete(c.(*abstraction.Context), id); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(nil).Send(c)
+}
+
+func paramID(c echo.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		return 0, errors.New("id must be a positive number")
+	}
+	return id, nil
+}
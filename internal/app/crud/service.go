@@ -0,0 +1,161 @@
+package crud
+
+import (
+	"errors"
+	"math"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/pkg/util/response"
+	"boilerplate/pkg/util/trxmanager"
+
+	"gorm.io/gorm"
+)
+
+// Service is the service interface served by Handler.
+type Service[T any, F abstraction.Filter, C any, U any] interface {
+	Find(ctx *abstraction.Context, f *F, p *abstraction.Pagination, fs *abstraction.Fieldset) ([]*T, *abstraction.PaginationInfo, error)
+	FindByID(ctx *abstraction.Context, id int) (*T, error)
+	Create(ctx *abstraction.Context, payload *C) (*T, error)
+	Update(ctx *abstraction.Context, id int, payload *U) (*T, error)
+	Delete(ctx *abstraction.Context, id int) error
+}
+
+// BaseService implements Service for the model T on top of an
+// abstraction.CRUD repository. C and U are the create and update payloads,
+// mapped to the model by NewModel and Patch.
+type BaseService[T any, F abstraction.Filter, C any, U any] struct {
+	Repository abstraction.CRUD[T, F]
+	DB         *gorm.DB
+
+	// NewModel builds the model to create from the payload.
+	NewModel func(ctx *abstraction.Context, payload *C) (*T, error)
+	// Patch copies the payload on the model loaded for an update.
+	Patch func(ctx *abstraction.Context, data *T, payload *U) error
+
+	// BeforeCreate, BeforeUpdate and BeforeDelete are optional checks, e.g.
+	// uniqueness or references. They run inside the transaction.
+	BeforeCreate func(ctx *abstraction.Context, payload *C) error
+	BeforeUpdate func(ctx *abstraction.Context, data *T, payload *U) error
+	BeforeDelete func(ctx *abstraction.Context, data *T) error
+}
+
+func (s *BaseService[T, F, C, U]) Find(ctx *abstraction.Context, f *F, p *abstraction.Pagination, fs *abstraction.Fieldset) (data []*T, info *abstraction.PaginationInfo, err error) {
+	if data, info, err = s.Repository.Find(ctx, f, p, fs); err != nil {
+		if errQuery := response.ErrorQuery(err); errQuery != nil {
+			return nil, nil, errQuery
+		}
+		return nil, nil, repositoryError(err)
+	}
+	if p != nil && p.PageSize != nil && !p.IsCursor() {
+		info.Pages = int(math.Ceil(float64(info.Count) / float64(*p.PageSize)))
+		if len(data) > *p.PageSize {
+			data = data[:len(data)-1]
+			info.MoreRecords = true
+		}
+	}
+	return
+}
+
+func (s *BaseService[T, F, C, U]) FindByID(ctx *abstraction.Context, id int) (data *T, err error) {
+	if data, err = s.Repository.FindByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.ErrorBuilder(&response.ErrorConstant.NotFound, err)
+		}
+		return nil, repositoryError(err)
+	}
+	return
+}
+
+func (s *BaseService[T, F, C, U]) Create(ctx *abstraction.Context, payload *C) (data *T, err error) {
+	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		if s.BeforeCreate != nil {
+			if err := s.BeforeCreate(ctx, payload); err != nil {
+				return unprocessable(err)
+			}
+		}
+		if data, err = s.NewModel(ctx, payload); err != nil {
+			return unprocessable(err)
+		}
+		if err = s.Repository.Create(ctx, data).Error; err != nil {
+			return repositoryError(err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *BaseService[T, F, C, U]) Update(ctx *abstraction.Context, id int, payload *U) (data *T, err error) {
+	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		if data, err = s.findForUpdate(ctx, id); err != nil {
+			return err
+		}
+		if s.BeforeUpdate != nil {
+			if err := s.BeforeUpdate(ctx, data, payload); err != nil {
+				return unprocessable(err)
+			}
+		}
+		if err = s.Patch(ctx, data, payload); err != nil {
+			return unprocessable(err)
+		}
+		if err = s.Repository.Update(ctx, data).Error; err != nil {
+			return repositoryError(err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *BaseService[T, F, C, U]) Delete(ctx *abstraction.Context, id int) error {
+	return trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		data, err := s.findForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if s.BeforeDelete != nil {
+			if err := s.BeforeDelete(ctx, data); err != nil {
+				return unprocessable(err)
+			}
+		}
+		if err := s.Repository.Delete(ctx, id).Error; err != nil {
+			return repositoryError(err)
+		}
+		return nil
+	})
+}
+
+// findForUpdate loads the row to write from the primary, locked until the
+// transaction of ctx ends so that no concurrent write is overwritten.
+func (s *BaseService[T, F, C, U]) findForUpdate(ctx *abstraction.Context, id int) (*T, error) {
+	data, err := s.Repository.FindByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.ErrorBuilder(&response.ErrorConstant.NotFound, err)
+		}
+		return nil, repositoryError(err)
+	}
+	return data, nil
+}
+
+// repositoryError translates the database errors, e.g. a duplicate or a
+// row still referenced, see response.ErrorDatabase, and maps any other error
+// to ErrorConstant.UnprocessableEntity.
+func repositoryError(err error) error {
+	if errDB := response.ErrorDatabase(err); errDB != nil {
+		return errDB
+	}
+	return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+}
+
+// unprocessable keeps the errors built by hooks and maps any other error to
+// ErrorConstant.UnprocessableEntity.
+func unprocessable(err error) error {
+	var re *response.Error
+	if errors.As(err, &re) {
+		return re
+	}
+	return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+}
@@ -0,0 +1,183 @@
+package group
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/app/crud"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/factory"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/util/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	service Service
+	crud    *crud.Handler[model.GroupEntityModel, dto.GroupFilter, dto.GroupCreateRequest, dto.GroupUpdateRequest]
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	service := NewService(f)
+	return &handler{
+		service: service,
+		crud:    crud.NewHandler[model.GroupEntityModel, dto.GroupFilter, dto.GroupCreateRequest, dto.GroupUpdateRequest](service),
+	}
+}
+
+// Find Group
+// @Summary Find Group
+// @Description Find Group
+// @Tags Group
+// @Produce json
+// @Security BearerAuth
+// @Param request query dto.GroupFilter true "request query"
+// @param request query abstraction.Pagination true "request query pagination"
+// @Success 200 {object} dto.FindGroupResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /group [get]
+func (h *handler) Find(c echo.Context) error {
+	return h.crud.Find(c)
+}
+
+// Find Group By ID
+// @Summary Find Group by ID
+// @Description Find Group by ID
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Success 200 {object} dto.GroupFindByIDResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /group/{id} [get]
+func (h *handler) FindByID(c echo.Context) error {
+	return h.crud.FindByID(c)
+}
+
+// Create Group
+// @Summary Create Group
+// @Description Create Group
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.GroupCreateRequest true "request body"
+// @Success 200 {object} dto.GroupCreateResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /group [post]
+func (h *handler) Create(c echo.Context) error {
+	return h.crud.Create(c)
+}
+
+// Update Group
+// @Summary Update Group
+// @Description Update Group
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Param request body dto.GroupUpdateRequest true "request body"
+// @Success 200 {object} dto.GroupUpdateResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /group/{id} [put]
+func (h *handler) Update(c echo.Context) error {
+	return h.crud.Update(c)
+}
+
+// Delete Group
+// @Summary Delete Group
+// @Description Delete Group and its memberships
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Success 200 {object} dto.GroupDeleteResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /group/{id} [delete]
+func (h *handler) Delete(c echo.Context) error {
+	return h.crud.Delete(c)
+}
+
+// Add Group Members
+// @Summary Add Group Members
+// @Description Add users of the caller's org unit subtree to a group, existing members are skipped
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Param request body dto.GroupMembersRequest true "request body"
+// @Success 200 {object} dto.GroupMembersResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /group/{id}/members [post]
+func (h *handler) AddMembers(c echo.Context) (err error) {
+	payload := new(dto.GroupMembersRequest)
+	if err = c.Bind(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err = c.Validate(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err).Send(c)
+	}
+	var data *dto.GroupMembersResponse
+	if data, err = h.service.AddMembers(c.(*abstraction.Context), payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
+
+// Remove Group Members
+// @Summary Remove Group Members
+// @Description Remove users of the caller's org unit subtree from a group
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Param request body dto.GroupMembersRequest true "request body"
+// @Success 200 {object} dto.GroupMembersResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /group/{id}/members [delete]
+func (h *handler) RemoveMembers(c echo.Context) (err error) {
+	payload := new(dto.GroupMembersRequest)
+	if err = c.Bind(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err = c.Validate(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err).Send(c)
+	}
+	var data *dto.GroupMembersResponse
+	if data, err = h.service.RemoveMembers(c.(*abstraction.Context), payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
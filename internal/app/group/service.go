@@ -0,0 +1,170 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/app/crud"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/factory"
+	"boilerplate/internal/model"
+	"boilerplate/internal/repository"
+	"boilerplate/pkg/redis"
+	"boilerplate/pkg/util/response"
+	"boilerplate/pkg/util/trxmanager"
+
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	crud.Service[model.GroupEntityModel, dto.GroupFilter, dto.GroupCreateRequest, dto.GroupUpdateRequest]
+	AddMembers(ctx *abstraction.Context, payload *dto.GroupMembersRequest) (*dto.GroupMembersResponse, error)
+	RemoveMembers(ctx *abstraction.Context, payload *dto.GroupMembersRequest) (*dto.GroupMembersResponse, error)
+}
+
+type service struct {
+	*crud.BaseService[model.GroupEntityModel, dto.GroupFilter, dto.GroupCreateRequest, dto.GroupUpdateRequest]
+
+	GroupRepository repository.Group
+	UserRepository  repository.User
+
+	DB *gorm.DB
+}
+
+func NewService(f *factory.Factory) Service {
+	s := &service{
+		GroupRepository: f.GroupRepository,
+		UserRepository:  f.UserRepository,
+
+		DB: f.DB,
+	}
+	s.BaseService = &crud.BaseService[model.GroupEntityModel, dto.GroupFilter, dto.GroupCreateRequest, dto.GroupUpdateRequest]{
+		Repository: f.GroupRepository,
+		DB:         f.DB,
+
+		NewModel:     s.newModel,
+		Patch:        s.patch,
+		BeforeCreate: s.beforeCreate,
+		BeforeUpdate: s.beforeUpdate,
+		BeforeDelete: s.beforeDelete,
+	}
+	return s
+}
+
+func (s *service) newModel(ctx *abstraction.Context, payload *dto.GroupCreateRequest) (*model.GroupEntityModel, error) {
+	data := &model.GroupEntityModel{}
+	data.Context = ctx
+	data.GroupEntity = model.GroupEntity{
+		Name:        payload.Name,
+		Type:        payload.Type,
+		Description: payload.Description,
+	}
+	return data, nil
+}
+
+func (s *service) patch(ctx *abstraction.Context, data *model.GroupEntityModel, payload *dto.GroupUpdateRequest) error {
+	data.Context = ctx
+	data.GroupEntity = model.GroupEntity{
+		Name:        payload.Name,
+		Type:        payload.Type,
+		Description: payload.Description,
+	}
+	data.ModifiedDate = nil
+	return nil
+}
+
+func (s *service) beforeCreate(ctx *abstraction.Context, payload *dto.GroupCreateRequest) error {
+	return s.checkName(ctx, 0, payload.Name)
+}
+
+func (s *service) beforeUpdate(ctx *abstraction.Context, data *model.GroupEntityModel, payload *dto.GroupUpdateRequest) error {
+	return s.checkName(ctx, data.ID, payload.Name)
+}
+
+// beforeDelete drops the cached scope of the members, deleting the group
+// deletes their membership.
+func (s *service) beforeDelete(ctx *abstraction.Context, data *model.GroupEntityModel) error {
+	userIDs, err := s.GroupRepository.FindMemberIDs(ctx, data.ID)
+	if err != nil {
+		return err
+	}
+	redis.ForgetAuthScope(ctx, userIDs...)
+	return nil
+}
+
+func (s *service) checkName(ctx *abstraction.Context, id int, name string) error {
+	existing, err := s.GroupRepository.FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != id {
+		return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Group %s already exist", name))
+	}
+	return nil
+}
+
+func (s *service) AddMembers(ctx *abstraction.Context, payload *dto.GroupMembersRequest) (data *dto.GroupMembersResponse, err error) {
+	if _, err = s.GroupRepository.FindByID(ctx, payload.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.ErrorBuilder(&response.ErrorConstant.NotFound, err)
+		}
+		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	if err = s.checkUsers(ctx, payload.UserIDs); err != nil {
+		return nil, err
+	}
+	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		result := s.GroupRepository.AddMembers(ctx, payload.ID, payload.UserIDs)
+		if result.Error != nil {
+			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, result.Error)
+		}
+		redis.ForgetAuthScope(ctx, payload.UserIDs...)
+		data = &dto.GroupMembersResponse{Affected: result.RowsAffected}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *service) RemoveMembers(ctx *abstraction.Context, payload *dto.GroupMembersRequest) (data *dto.GroupMembersResponse, err error) {
+	if _, err = s.GroupRepository.FindByID(ctx, payload.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.ErrorBuilder(&response.ErrorConstant.NotFound, err)
+		}
+		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	if err = s.checkUsers(ctx, payload.UserIDs); err != nil {
+		return nil, err
+	}
+	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		result := s.GroupRepository.RemoveMembers(ctx, payload.ID, payload.UserIDs)
+		if result.Error != nil {
+			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, result.Error)
+		}
+		redis.ForgetAuthScope(ctx, payload.UserIDs...)
+		data = &dto.GroupMembersResponse{Affected: result.RowsAffected}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// checkUsers makes sure the users are in the caller's subtree, the members of
+// a group are managed for those users only.
+func (s *service) checkUsers(ctx *abstraction.Context, userIDs []int) error {
+	missing, err := s.UserRepository.FindMissingIDs(ctx, userIDs)
+	if err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	if len(missing) > 0 {
+		return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Users %v not found", missing), map[string]interface{}{"user_ids": missing})
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"errors"
+
+	"boilerplate/internal/factory"
+	"boilerplate/pkg/util/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	factory *factory.Factory
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		factory: f,
+	}
+}
+
+// Live
+// @Summary Liveness probe
+// @Description Succeeds while the process serves requests, whatever the state of its dependencies
+// @Tags Health
+// @Produce json
+// @Success 200 {object} response.Success
+// @Router /health/live [get]
+func (h *handler) Live(c echo.Context) error {
+	return response.SuccessResponse(map[string]string{"status": "up"}).Send(c)
+}
+
+// Ready
+// @Summary Readiness probe
+// @Description Lists the state of the dependencies, fails while a required dependency is down. Optional dependencies that are down only degrade the service.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} dto.HealthResponseDoc
+// @Failure 503 {object} dto.HealthResponseDoc
+// @Router /health/ready [get]
+func (h *handler) Ready(c echo.Context) error {
+	deps := h.factory.Dependencies
+	if deps == nil {
+		return response.SuccessResponse(nil).Send(c)
+	}
+	if !deps.Ready() {
+		res := response.ErrorConstant.ServiceUnavailableError
+		return response.ErrorBuilder(&res, errors.New("a required dependency is down")).WithData(deps.Status()).Send(c)
+	}
+	return response.SuccessResponse(deps.Status()).Send(c)
+}
@@ -0,0 +1,11 @@
+package health
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// Route ...
+func (h *handler) Route(v *echo.Group) {
+	v.GET("/live", h.Live)
+	v.GET("/ready", h.Ready)
+}
@@ -0,0 +1,181 @@
+package orgunit
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/factory"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/util/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+// Find Org Unit
+// @Summary Find Org Unit
+// @Description Find Org Unit in the caller's subtree
+// @Tags OrgUnit
+// @Produce json
+// @Security BearerAuth
+// @Param request query dto.OrgUnitFilter true "request query"
+// @param request query abstraction.Pagination true "request query pagination"
+// @Success 200 {object} dto.FindOrgUnitResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /org-unit [get]
+func (h *handler) Find(c echo.Context) (err error) {
+	f := new(dto.OrgUnitFilter)
+	if err = c.Bind(f); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+	if f.Conditions, err = abstraction.ParseConditions(c.QueryParams(), f); err != nil {
+		return response.ErrorQuery(err).Send(c)
+	}
+
+	p := new(abstraction.Pagination)
+	if err = c.Bind(p); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+
+	var (
+		data []*model.OrgUnitEntityModel
+		info *abstraction.PaginationInfo
+	)
+	if data, info, err = h.service.Find(c.(*abstraction.Context), f, p); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+
+	return response.SuccessResponse(data).WithPagination(info).Send(c)
+}
+
+// Find Org Unit By ID
+// @Summary Find Org Unit by ID
+// @Description Find Org Unit by ID
+// @Tags OrgUnit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Success 200 {object} dto.OrgUnitFindByIDResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /org-unit/{id} [get]
+func (h *handler) FindByID(c echo.Context) (err error) {
+	payload := new(dto.OrgUnitFindByIDRequest)
+	if err = c.Bind(payload); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+	if err = c.Validate(payload); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+	var data *model.OrgUnitEntityModel
+	if data, err = h.service.FindByID(c.(*abstraction.Context), payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
+
+// Create Org Unit
+// @Summary Create Org Unit
+// @Description Create Org Unit
+// @Tags OrgUnit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.OrgUnitCreateRequest true "request body"
+// @Success 200 {object} dto.OrgUnitCreateResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /org-unit [post]
+func (h *handler) Create(c echo.Context) (err error) {
+	payload := new(dto.OrgUnitCreateRequest)
+	if err = c.Bind(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err = c.Validate(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err).Send(c)
+	}
+	var data *model.OrgUnitEntityModel
+	if data, err = h.service.Create(c.(*abstraction.Context), payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
+
+// Update Org Unit
+// @Summary Update Org Unit
+// @Description Update Org Unit
+// @Tags OrgUnit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Param request body dto.OrgUnitUpdateRequest true "request body"
+// @Success 200 {object} dto.OrgUnitUpdateResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /org-unit/{id} [put]
+func (h *handler) Update(c echo.Context) (err error) {
+	payload := new(dto.OrgUnitUpdateRequest)
+	if err = c.Bind(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err = c.Validate(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err).Send(c)
+	}
+	var data *model.OrgUnitEntityModel
+	if data, err = h.service.Update(c.(*abstraction.Context), payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
+
+// Delete Org Unit
+// @Summary Delete Org Unit
+// @Description Delete Org Unit, rejected while it has child units or users
+// @Tags OrgUnit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Success 200 {object} dto.OrgUnitDeleteResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /org-unit/{id} [delete]
+func (h *handler) Delete(c echo.Context) error {
+	payload := new(dto.OrgUnitDeleteRequest)
+	if err := c.Bind(payload); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+	if err := h.service.Delete(c.(*abstraction.Context), payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(nil).Send(c)
+}
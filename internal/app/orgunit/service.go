@@ -0,0 +1,188 @@
+package orgunit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/factory"
+	"boilerplate/internal/model"
+	"boilerplate/internal/repository"
+	"boilerplate/pkg/util/response"
+	"boilerplate/pkg/util/trxmanager"
+
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	Find(ctx *abstraction.Context, f *dto.OrgUnitFilter, p *abstraction.Pagination) ([]*model.OrgUnitEntityModel, *abstraction.PaginationInfo, error)
+	FindByID(ctx *abstraction.Context, payload *dto.OrgUnitFindByIDRequest) (*model.OrgUnitEntityModel, error)
+	Create(ctx *abstraction.Context, payload *dto.OrgUnitCreateRequest) (*model.OrgUnitEntityModel, error)
+	Update(ctx *abstraction.Context, payload *dto.OrgUnitUpdateRequest) (*model.OrgUnitEntityModel, error)
+	Delete(ctx *abstraction.Context, payload *dto.OrgUnitDeleteRequest) error
+}
+
+type service struct {
+	OrgUnitRepository repository.OrgUnit
+
+	DB *gorm.DB
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		OrgUnitRepository: f.OrgUnitRepository,
+
+		DB: f.DB,
+	}
+}
+
+func (s *service) Find(ctx *abstraction.Context, f *dto.OrgUnitFilter, p *abstraction.Pagination) (data []*model.OrgUnitEntityModel, info *abstraction.PaginationInfo, err error) {
+	if data, info, err = s.OrgUnitRepository.Find(ctx, f, p); err != nil {
+		if errQuery := response.ErrorQuery(err); errQuery != nil {
+			return nil, nil, errQuery
+		}
+		return nil, nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	if p != nil && p.PageSize != nil {
+		info.Pages = int(math.Ceil(float64(info.Count) / float64(*p.PageSize)))
+		if len(data) > *p.PageSize {
+			data = data[:len(data)-1]
+			info.MoreRecords = true
+		}
+	}
+	return
+}
+
+func (s *service) FindByID(ctx *abstraction.Context, payload *dto.OrgUnitFindByIDRequest) (data *model.OrgUnitEntityModel, err error) {
+	if data, err = s.OrgUnitRepository.FindByID(ctx, payload.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.ErrorBuilder(&response.ErrorConstant.NotFound, err)
+		}
+		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	return
+}
+
+func (s *service) Create(ctx *abstraction.Context, payload *dto.OrgUnitCreateRequest) (data *model.OrgUnitEntityModel, err error) {
+	if err = s.checkParent(ctx, payload.ParentID); err != nil {
+		return nil, err
+	}
+	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		data = &model.OrgUnitEntityModel{}
+		data.Context = ctx
+		data.OrgUnitEntity = model.OrgUnitEntity{
+			ParentID: payload.ParentID,
+			Code:     payload.Code,
+			Name:     payload.Name,
+		}
+		if err = s.OrgUnitRepository.Create(ctx, data).Error; err != nil {
+			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *service) Update(ctx *abstraction.Context, payload *dto.OrgUnitUpdateRequest) (data *model.OrgUnitEntityModel, err error) {
+	if data, err = s.OrgUnitRepository.FindByID(ctx, payload.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.ErrorBuilder(&response.ErrorConstant.NotFound, err)
+		}
+		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+
+	moved := !sameParent(data.ParentID, payload.ParentID)
+	if moved {
+		if err = s.checkParent(ctx, payload.ParentID); err != nil {
+			return nil, err
+		}
+		if payload.ParentID != nil {
+			var cyclic bool
+			if cyclic, err = s.OrgUnitRepository.IsDescendant(ctx, payload.ID, *payload.ParentID); err != nil {
+				return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+			}
+			if cyclic {
+				return nil, response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, "Org unit can not be moved under itself or its descendants")
+			}
+		}
+	}
+
+	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		data.Context = ctx
+		data.OrgUnitEntity = model.OrgUnitEntity{
+			ParentID: payload.ParentID,
+			Code:     payload.Code,
+			Name:     payload.Name,
+		}
+		data.ModifiedDate = nil
+		if err = s.OrgUnitRepository.Update(ctx, data).Error; err != nil {
+			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+		}
+		if moved {
+			if err = s.OrgUnitRepository.Move(ctx, payload.ID, payload.ParentID); err != nil {
+				return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *service) Delete(ctx *abstraction.Context, payload *dto.OrgUnitDeleteRequest) error {
+	data, err := s.OrgUnitRepository.FindByID(ctx, payload.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.ErrorBuilder(&response.ErrorConstant.NotFound, err)
+		}
+		return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	return trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		children, err := s.OrgUnitRepository.CountChildren(ctx, payload.ID)
+		if err != nil {
+			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+		}
+		users, err := s.OrgUnitRepository.CountUsers(ctx, payload.ID)
+		if err != nil {
+			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+		}
+		if children > 0 || users > 0 {
+			return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Org unit %s still has %d child unit(s) and %d user(s)", data.Name, children, users))
+		}
+		if err = s.OrgUnitRepository.Delete(ctx, payload.ID).Error; err != nil {
+			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+		}
+		return nil
+	})
+}
+
+// checkParent makes sure the caller may place an org unit under parentID.
+// Only global callers may create roots.
+func (s *service) checkParent(ctx *abstraction.Context, parentID *int) error {
+	if parentID == nil {
+		if ctx.Auth != nil && !ctx.Auth.Global {
+			return response.ErrorBuilder(&response.ErrorConstant.Forbidden, errors.New("only global users can manage root org units"))
+		}
+		return nil
+	}
+	if _, err := s.OrgUnitRepository.FindByID(ctx, *parentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Parent org unit %d not found", *parentID))
+		}
+		return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	return nil
+}
+
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,116 @@
+package role
+
+import (
+	"boilerplate/internal/app/crud"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/factory"
+	"boilerplate/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	crud *crud.Handler[model.RoleEntityModel, dto.RoleFilter, dto.RoleCreateRequest, dto.RoleUpdateRequest]
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		crud: crud.NewHandler(NewService(f)),
+	}
+}
+
+// Find Role
+// @Summary Find Role
+// @Description Find Role
+// @Tags Role
+// @Produce json
+// @Security BearerAuth
+// @Param request query dto.RoleFilter true "request query"
+// @param request query abstraction.Pagination true "request query pagination"
+// @Success 200 {object} dto.FindRoleResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /role [get]
+func (h *handler) Find(c echo.Context) error {
+	return h.crud.Find(c)
+}
+
+// Find Role By ID
+// @Summary Find Role by ID
+// @Description Find Role by ID
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Success 200 {object} dto.RoleFindByIDResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /role/{id} [get]
+func (h *handler) FindByID(c echo.Context) error {
+	return h.crud.FindByID(c)
+}
+
+// Create Role
+// @Summary Create Role
+// @Description Create Role
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RoleCreateRequest true "request body"
+// @Success 200 {object} dto.RoleCreateResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /role [post]
+func (h *handler) Create(c echo.Context) error {
+	return h.crud.Create(c)
+}
+
+// Update Role
+// @Summary Update Role
+// @Description Update Role
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Param request body dto.RoleUpdateRequest true "request body"
+// @Success 200 {object} dto.RoleUpdateResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /role/{id} [put]
+func (h *handler) Update(c echo.Context) error {
+	return h.crud.Update(c)
+}
+
+// Delete Role
+// @Summary Delete Role
+// @Description Delete Role, rejected while users still have the role
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "id path"
+// @Success 200 {object} dto.RoleDeleteResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 404 {object} response.ErrorResponse404
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /role/{id} [delete]
+func (h *handler) Delete(c echo.Context) error {
+	return h.crud.Delete(c)
+}
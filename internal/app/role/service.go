@@ -0,0 +1,127 @@
+package role
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/app/crud"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/factory"
+	"boilerplate/internal/model"
+	"boilerplate/internal/repository"
+	"boilerplate/pkg/redis"
+	"boilerplate/pkg/util/response"
+
+	"gorm.io/gorm"
+)
+
+type Service = crud.Service[model.RoleEntityModel, dto.RoleFilter, dto.RoleCreateRequest, dto.RoleUpdateRequest]
+
+type service struct {
+	RoleRepository repository.Role
+}
+
+func NewService(f *factory.Factory) Service {
+	s := &service{
+		RoleRepository: f.RoleRepository,
+	}
+	return &crud.BaseService[model.RoleEntityModel, dto.RoleFilter, dto.RoleCreateRequest, dto.RoleUpdateRequest]{
+		Repository: f.RoleRepository,
+		DB:         f.DB,
+
+		NewModel:     s.newModel,
+		Patch:        s.patch,
+		BeforeCreate: s.beforeCreate,
+		BeforeUpdate: s.beforeUpdate,
+		BeforeDelete: s.beforeDelete,
+	}
+}
+
+func (s *service) newModel(ctx *abstraction.Context, payload *dto.RoleCreateRequest) (*model.RoleEntityModel, error) {
+	data := &model.RoleEntityModel{}
+	data.Context = ctx
+	data.RoleEntity = model.RoleEntity{
+		Name:        payload.Name,
+		Description: payload.Description,
+		IsGlobal:    payload.IsGlobal,
+	}
+	return data, nil
+}
+
+func (s *service) patch(ctx *abstraction.Context, data *model.RoleEntityModel, payload *dto.RoleUpdateRequest) error {
+	data.Context = ctx
+	data.RoleEntity = model.RoleEntity{
+		Name:        payload.Name,
+		Description: payload.Description,
+		IsGlobal:    payload.IsGlobal,
+	}
+	data.ModifiedDate = nil
+	return nil
+}
+
+func (s *service) beforeCreate(ctx *abstraction.Context, payload *dto.RoleCreateRequest) error {
+	if err := checkGlobal(ctx, payload.IsGlobal); err != nil {
+		return err
+	}
+	return s.checkName(ctx, 0, payload.Name)
+}
+
+func (s *service) beforeUpdate(ctx *abstraction.Context, data *model.RoleEntityModel, payload *dto.RoleUpdateRequest) error {
+	if err := checkGlobal(ctx, data.IsGlobal || payload.IsGlobal); err != nil {
+		return err
+	}
+	if err := s.checkName(ctx, data.ID, payload.Name); err != nil {
+		return err
+	}
+	if payload.IsGlobal != data.IsGlobal {
+		// the users of the role gain or lose global access
+		userIDs, err := s.RoleRepository.FindUserIDs(ctx, data.ID)
+		if err != nil {
+			return err
+		}
+		redis.ForgetAuthScope(ctx, userIDs...)
+	}
+	return nil
+}
+
+// beforeDelete rejects global roles deleted by non-global callers and roles
+// still assigned to users. The foreign key on m_user.role_id rejects the
+// delete as well, this only gives a readable error.
+func (s *service) beforeDelete(ctx *abstraction.Context, data *model.RoleEntityModel) error {
+	if err := checkGlobal(ctx, data.IsGlobal); err != nil {
+		return err
+	}
+	count, err := s.RoleRepository.CountUsers(ctx, data.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Role %s is still assigned to %d user(s)", data.Name, count))
+	}
+	return nil
+}
+
+func (s *service) checkName(ctx *abstraction.Context, id int, name string) error {
+	existing, err := s.RoleRepository.FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != id {
+		return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Role %s already exist", name))
+	}
+	return nil
+}
+
+// checkGlobal keeps callers restricted to an org unit subtree from granting
+// global access and from changing the roles that grant it.
+func checkGlobal(ctx *abstraction.Context, isGlobal bool) error {
+	if isGlobal && ctx.Auth != nil && !ctx.Auth.Global {
+		return response.ErrorBuilder(&response.ErrorConstant.Forbidden, errors.New("only global users can manage global roles"))
+	}
+	return nil
+}
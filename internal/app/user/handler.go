@@ -27,6 +27,7 @@ func NewHandler(f *factory.Factory) *handler {
 // @Produce json
 // @Param request query dto.UserFilter true "request query"
 // @param request query abstraction.Pagination true "request query pagination"
+// @param request query abstraction.Fieldset true "request query fields and relations"
 // @Success 200 {object} dto.FindUserResponseDoc
 // @Failure 400 {object} response.ErrorResponse400
 // @Failure 401 {object} response.ErrorResponse401
@@ -39,21 +40,34 @@ func (h *handler) Find(c echo.Context) (err error) {
 	if err := c.Bind(f); err != nil {
 		return response.ErrorBadRequest(err).Send(c)
 	}
+	if f.Conditions, err = abstraction.ParseConditions(c.QueryParams(), f); err != nil {
+		return response.ErrorQuery(err).Send(c)
+	}
 
 	p := new(abstraction.Pagination)
 	if err := c.Bind(p); err != nil {
 		return response.ErrorBadRequest(err).Send(c)
 	}
 
+	fs := new(abstraction.Fieldset)
+	if err := c.Bind(fs); err != nil {
+		return response.ErrorBadRequest(err).Send(c)
+	}
+
 	var (
 		data []*model.UserEntityModel
 		info *abstraction.PaginationInfo
 	)
-	if data, info, err = h.service.Find(c.(*abstraction.Context), f, p); err != nil {
+	if data, info, err = h.service.Find(c.(*abstraction.Context), f, p, fs); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+
+	projected, err := fs.Project(data)
+	if err != nil {
 		return response.ErrorResponse(err).Send(c)
 	}
 
-	return response.SuccessResponse(data).WithPagination(info).Send(c)
+	return response.SuccessResponse(projected).WithPagination(info).Send(c)
 }
 
 // Find User By ID
@@ -94,10 +108,12 @@ func (h *handler) FindByID(c echo.Context) (err error) {
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.UserCreateRequest true "request body"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
 // @Success 200 {object} dto.UserCreateResponseDoc
 // @Failure 400 {object} response.ErrorResponse400
 // @Failure 401 {object} response.ErrorResponse401
 // @Failure 404 {object} response.ErrorResponse404
+// @Failure 409 {object} response.ErrorResponse409
 // @Failure 422 {object} response.ErrorResponse422
 // @Failure 500 {object} response.ErrorResponse500
 // @Router /user [post]
@@ -125,10 +141,12 @@ func (h *handler) Create(c echo.Context) (err error) {
 // @Security BearerAuth
 // @Param id path int true "id path"
 // @Param request body dto.UserUpdateRequest true "request body"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
 // @Success 200 {object} dto.UserUpdateResponseDoc
 // @Failure 400 {object} response.ErrorResponse400
 // @Failure 401 {object} response.ErrorResponse401
 // @Failure 404 {object} response.ErrorResponse404
+// @Failure 409 {object} response.ErrorResponse409
 // @Failure 422 {object} response.ErrorResponse422
 // @Failure 500 {object} response.ErrorResponse500
 // @Router /user/{id} [put]
@@ -159,6 +177,7 @@ func (h *handler) Update(c echo.Context) (err error) {
 // @Failure 400 {object} response.ErrorResponse400
 // @Failure 401 {object} response.ErrorResponse401
 // @Failure 404 {object} response.ErrorResponse404
+// @Failure 409 {object} response.ErrorResponse409
 // @Failure 422 {object} response.ErrorResponse422
 // @Failure 500 {object} response.ErrorResponse500
 // @Router /user/{id} [delete]
@@ -175,3 +194,33 @@ func (h *handler) Delete(c echo.Context) error {
 	}
 	return response.SuccessResponse(nil).Send(c)
 }
+
+// Batch User
+// @Summary Batch create, update and delete User
+// @Description Run a list of create, update and delete operations, either atomically in one transaction or best-effort
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UserBatchRequest true "request body"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
+// @Success 200 {object} dto.UserBatchResponseDoc
+// @Failure 400 {object} response.ErrorResponse400
+// @Failure 401 {object} response.ErrorResponse401
+// @Failure 422 {object} response.ErrorResponse422
+// @Failure 500 {object} response.ErrorResponse500
+// @Router /user/batch [post]
+func (h *handler) Batch(c echo.Context) (err error) {
+	payload := new(dto.UserBatchRequest)
+	if err = c.Bind(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err = c.Validate(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err).Send(c)
+	}
+	var data []*dto.UserBatchResult
+	if data, err = h.service.Batch(c.(*abstraction.Context), payload); err != nil {
+		return response.ErrorResponse(err).Send(c)
+	}
+	return response.SuccessResponse(data).Send(c)
+}
@@ -11,6 +11,7 @@ func (h *handler) Route(v *echo.Group) {
 	v.GET("", h.Find, middleware.Authentication)
 	v.GET("/:id", h.FindByID, middleware.Authentication)
 	v.POST("", h.Create, middleware.Authentication)
+	v.POST("/batch", h.Batch, middleware.Authentication)
 	v.PUT("/:id", h.Update, middleware.Authentication)
 	v.DELETE("/:id", h.Delete, middleware.Authentication)
 }
@@ -1,16 +1,21 @@
 package user
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"net/http"
 
 	"boilerplate/internal/abstraction"
+	"boilerplate/internal/config"
 	"boilerplate/internal/dto"
 	"boilerplate/internal/factory"
 	"boilerplate/internal/model"
 	"boilerplate/internal/repository"
+	"boilerplate/pkg/outbox"
+	"boilerplate/pkg/redis"
+	"boilerplate/pkg/util/priority"
 	"boilerplate/pkg/util/response"
 	"boilerplate/pkg/util/trxmanager"
 
@@ -18,32 +23,43 @@ import (
 )
 
 type Service interface {
-	Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.Pagination) ([]*model.UserEntityModel, *abstraction.PaginationInfo, error)
+	Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.Pagination, fs *abstraction.Fieldset) ([]*model.UserEntityModel, *abstraction.PaginationInfo, error)
 	FindByID(ctx *abstraction.Context, payload *dto.UserFindByIDRequest) (*model.UserEntityModel, error)
 	Create(ctx *abstraction.Context, payload *dto.UserCreateRequest) (*model.UserEntityModel, error)
 	Update(ctx *abstraction.Context, payload *dto.UserUpdateRequest) (*model.UserEntityModel, error)
 	Delete(ctx *abstraction.Context, payload *dto.UserDeleteRequest) error
+	Batch(ctx *abstraction.Context, payload *dto.UserBatchRequest) ([]*dto.UserBatchResult, error)
 }
 
 type service struct {
-	UserRepository repository.User
+	UserRepository    repository.User
+	RoleRepository    repository.Role
+	OrgUnitRepository repository.OrgUnit
 
 	DB *gorm.DB
 }
 
 func NewService(f *factory.Factory) Service {
 	return &service{
-		UserRepository: f.UserRepository,
+		UserRepository:    f.UserRepository,
+		RoleRepository:    f.RoleRepository,
+		OrgUnitRepository: f.OrgUnitRepository,
 
 		DB: f.DB,
 	}
 }
 
-func (s *service) Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.Pagination) (data []*model.UserEntityModel, info *abstraction.PaginationInfo, err error) {
-	if data, info, err = s.UserRepository.Find(ctx, f, p); err != nil {
+func (s *service) Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.Pagination, fs *abstraction.Fieldset) (data []*model.UserEntityModel, info *abstraction.PaginationInfo, err error) {
+	if data, info, err = s.UserRepository.Find(ctx, f, p, fs); err != nil {
+		if errQuery := response.ErrorQuery(err); errQuery != nil {
+			return nil, nil, errQuery
+		}
+		if errDB := response.ErrorDatabase(err); errDB != nil {
+			return nil, nil, errDB
+		}
 		return nil, nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 	}
-	if p != nil && p.PageSize != nil {
+	if p != nil && p.PageSize != nil && !p.IsCursor() {
 		info.Pages = int(math.Ceil(float64(info.Count) / float64(*p.PageSize)))
 		if len(data) > *p.PageSize {
 			data = data[:len(data)-1]
@@ -64,24 +80,44 @@ func (s *service) FindByID(ctx *abstraction.Context, payload *dto.UserFindByIDRe
 }
 
 func (s *service) Create(ctx *abstraction.Context, payload *dto.UserCreateRequest) (data *model.UserEntityModel, err error) {
-	if data, err = s.UserRepository.FindByUsernameOrEmail(ctx, payload.Username, payload.Email); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	if payload.OrgUnitID == nil && ctx.Auth != nil && !ctx.Auth.Global {
+		payload.OrgUnitID = ctx.Auth.OrgUnitID
+	}
+	if err = s.checkOrgUnit(ctx, payload.OrgUnitID); err != nil {
+		return nil, err
 	}
-	if data.ID != 0 {
-		return nil, response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Email %s or username %s already exist", payload.Email, payload.Username))
+	if err = s.checkRole(ctx, payload.RoleID); err != nil {
+		return nil, err
 	}
+	// the unique indexes of username and email reject duplicates, see
+	// response.ErrorDatabase
 	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
 		data = &model.UserEntityModel{}
 		data.Context = ctx
 		data.UserEntity = model.UserEntity{
-			Name:     payload.Name,
-			Email:    payload.Email,
-			RoleID:   payload.RoleID,
-			Username: payload.Username,
-			Password: payload.Password,
-			IsActive: payload.IsActive,
+			Name:      payload.Name,
+			Email:     payload.Email,
+			RoleID:    payload.RoleID,
+			OrgUnitID: payload.OrgUnitID,
+			Username:  payload.Username,
+			Password:  payload.Password,
+			IsActive:  payload.IsActive,
 		}
 		if err = s.UserRepository.Create(ctx, &data).Error; err != nil {
+			if errDB := response.ErrorDatabase(err); errDB != nil {
+				return errDB
+			}
+			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+		}
+		if err = outbox.Publish(ctx, dto.UserCreated{
+			ID:        data.ID,
+			Username:  data.Username,
+			Name:      data.Name,
+			Email:     data.Email,
+			RoleID:    data.RoleID,
+			OrgUnitID: data.OrgUnitID,
+			IsActive:  data.IsActive == nil || *data.IsActive,
+		}); err != nil {
 			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 		}
 		return nil
@@ -98,12 +134,19 @@ func (s *service) Update(ctx *abstraction.Context, payload *dto.UserUpdateReques
 		}
 		return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 	}
-	if data.Username != payload.Username || data.Email != payload.Email {
-		if data, err = s.UserRepository.FindByUsernameOrEmail(ctx, payload.Username, payload.Email); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
-		}
-		if data.ID != 0 {
-			return nil, response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Email %s or username %s already exist", payload.Email, payload.Username))
+	wasActive := data.IsActive == nil || *data.IsActive
+	// the identity of the updated row, the payload leaves unchanged fields
+	// empty
+	username := priority.PriorityString(payload.Username, data.Username)
+	email := priority.PriorityString(payload.Email, data.Email)
+	if payload.OrgUnitID == nil {
+		payload.OrgUnitID = data.OrgUnitID
+	} else if err = s.checkOrgUnit(ctx, payload.OrgUnitID); err != nil {
+		return nil, err
+	}
+	if payload.RoleID != 0 && payload.RoleID != data.RoleID {
+		if err = s.checkRole(ctx, payload.RoleID); err != nil {
+			return nil, err
 		}
 	}
 	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
@@ -111,16 +154,30 @@ func (s *service) Update(ctx *abstraction.Context, payload *dto.UserUpdateReques
 		data.Context = ctx
 		data.ID = payload.ID
 		data.UserEntity = model.UserEntity{
-			Name:     payload.Name,
-			Email:    payload.Email,
-			RoleID:   payload.RoleID,
-			Username: payload.Username,
-			Password: payload.Password,
-			IsActive: payload.IsActive,
+			Name:      payload.Name,
+			Email:     payload.Email,
+			RoleID:    payload.RoleID,
+			OrgUnitID: payload.OrgUnitID,
+			Username:  payload.Username,
+			Password:  payload.Password,
+			IsActive:  payload.IsActive,
 		}
 		if err = s.UserRepository.Update(ctx, data).Error; err != nil {
+			if errDB := response.ErrorDatabase(err); errDB != nil {
+				return errDB
+			}
 			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 		}
+		redis.ForgetAuthScope(ctx, data.ID)
+		if wasActive && payload.IsActive != nil && !*payload.IsActive {
+			if err = outbox.Publish(ctx, dto.UserDeactivated{
+				ID:       data.ID,
+				Username: username,
+				Email:    email,
+			}); err != nil {
+				return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+			}
+		}
 		return nil
 	}); err != nil {
 		return nil, err
@@ -128,6 +185,36 @@ func (s *service) Update(ctx *abstraction.Context, payload *dto.UserUpdateReques
 	return
 }
 
+// checkOrgUnit makes sure the org unit is in the caller's subtree.
+func (s *service) checkOrgUnit(ctx *abstraction.Context, orgUnitID *int) error {
+	if orgUnitID == nil {
+		return nil
+	}
+	if _, err := s.OrgUnitRepository.FindByID(ctx, *orgUnitID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Org unit %d not found", *orgUnitID))
+		}
+		return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	return nil
+}
+
+// checkRole makes sure the role belongs to the caller's tenant, and that only
+// global callers assign roles granting global access.
+func (s *service) checkRole(ctx *abstraction.Context, roleID int) error {
+	role, err := s.RoleRepository.FindByID(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, fmt.Sprintf("Role %d not found", roleID))
+		}
+		return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
+	}
+	if role.IsGlobal && ctx.Auth != nil && !ctx.Auth.Global {
+		return response.ErrorBuilder(&response.ErrorConstant.Forbidden, errors.New("only global users can assign global roles"))
+	}
+	return nil
+}
+
 func (s *service) Delete(ctx *abstraction.Context, payload *dto.UserDeleteRequest) error {
 	if _, err := s.UserRepository.FindByID(ctx, payload.ID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -137,8 +224,107 @@ func (s *service) Delete(ctx *abstraction.Context, payload *dto.UserDeleteReques
 	}
 	return trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
 		if err := s.UserRepository.Delete(ctx, &dto.UserFilter{ID: []int{payload.ID}}).Error; err != nil {
+			if errDB := response.ErrorDatabase(err); errDB != nil {
+				return errDB
+			}
 			return response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, err)
 		}
+		redis.ForgetAuthScope(ctx, payload.ID)
 		return nil
 	})
 }
+
+// Batch runs the operations in order. In atomic mode all of them share a single
+// transaction and the first failure rolls back the whole batch, otherwise every
+// operation is committed on its own and failures don't stop the rest.
+func (s *service) Batch(ctx *abstraction.Context, payload *dto.UserBatchRequest) (results []*dto.UserBatchResult, err error) {
+	if maxSize := config.App().BatchMaxSize; len(payload.Operations) > maxSize {
+		return nil, response.ErrorBuilder(&response.ErrorConstant.Validation, fmt.Errorf("batch size %d exceeds the limit of %d", len(payload.Operations), maxSize), map[string]interface{}{"operations": maxSize})
+	}
+
+	results = make([]*dto.UserBatchResult, len(payload.Operations))
+	for i, op := range payload.Operations {
+		results[i] = &dto.UserBatchResult{Index: i, Op: op.Op}
+	}
+
+	if !payload.Atomic {
+		for i, op := range payload.Operations {
+			data, err := s.batchOperation(ctx, op)
+			s.batchResult(results[i], data, err)
+		}
+		return
+	}
+
+	failed := -1
+	if err = trxmanager.New(s.DB).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		for i, op := range payload.Operations {
+			data, err := s.batchOperation(ctx, op)
+			if err != nil {
+				failed = i
+				return err
+			}
+			results[i].Data = data
+		}
+		return nil
+	}); err != nil {
+		if failed < 0 {
+			return nil, response.ErrorResponse(err)
+		}
+		for i, result := range results {
+			if i == failed {
+				s.batchResult(result, nil, err)
+				continue
+			}
+			result.Data = nil
+			s.batchResult(result, nil, response.CustomErrorBuilder(http.StatusFailedDependency, response.E_UNPROCESSABLE_ENTITY, fmt.Sprintf("Rolled back because operation %d failed", failed)))
+		}
+		return results, nil
+	}
+	for _, result := range results {
+		result.Status = http.StatusOK
+	}
+	return
+}
+
+func (s *service) batchOperation(ctx *abstraction.Context, op *dto.UserBatchOperation) (interface{}, error) {
+	switch op.Op {
+	case dto.BatchOperationCreate:
+		payload := new(dto.UserCreateRequest)
+		if err := s.batchPayload(ctx, op, payload); err != nil {
+			return nil, err
+		}
+		return s.Create(ctx, payload)
+	case dto.BatchOperationUpdate:
+		payload := &dto.UserUpdateRequest{ID: op.ID}
+		if err := s.batchPayload(ctx, op, payload); err != nil {
+			return nil, err
+		}
+		return s.Update(ctx, payload)
+	case dto.BatchOperationDelete:
+		return nil, s.Delete(ctx, &dto.UserDeleteRequest{ID: op.ID})
+	}
+	return nil, response.ErrorBuilder(&response.ErrorConstant.Validation, fmt.Errorf("unknown operation %q", op.Op))
+}
+
+func (s *service) batchPayload(ctx *abstraction.Context, op *dto.UserBatchOperation, payload interface{}) error {
+	if len(op.Data) > 0 {
+		if err := json.Unmarshal(op.Data, payload); err != nil {
+			return response.ErrorBuilder(&response.ErrorConstant.BadRequest, err, err.Error())
+		}
+	}
+	if err := ctx.Validate(payload); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.Validation, err)
+	}
+	return nil
+}
+
+func (s *service) batchResult(result *dto.UserBatchResult, data interface{}, err error) {
+	if err != nil {
+		e := response.ErrorResponse(err)
+		result.Status = e.Code
+		result.Error = e.Response
+		return
+	}
+	result.Status = http.StatusOK
+	result.Data = data
+}
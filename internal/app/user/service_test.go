@@ -0,0 +1,108 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/model"
+	"boilerplate/internal/repository"
+	"boilerplate/pkg/database/migrations"
+	"boilerplate/pkg/database/sqlite"
+	"boilerplate/pkg/util/response"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm/logger"
+)
+
+// testService returns a service on a migrated database holding the root org
+// unit 1 and the roles 1, global, and 2.
+func testService(t *testing.T) *service {
+	db, err := sqlite.Config{Name: t.Name(), Path: sqlite.Memory, Logger: logger.Discard}.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, connection := range migrations.Connections() {
+		m, err := migrations.New(connection, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = m.Up(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = abstraction.RegisterTenant(db); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &service{
+		UserRepository:    repository.NewUser(db),
+		RoleRepository:    repository.NewRole(db),
+		OrgUnitRepository: repository.NewOrgUnit(db),
+		DB:                db,
+	}
+	ctx := testContext(true)
+	if err = s.OrgUnitRepository.Create(ctx, &model.OrgUnitEntityModel{OrgUnitEntity: model.OrgUnitEntity{Name: "root"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, role := range []model.RoleEntity{{Name: "Administrator", IsGlobal: true}, {Name: "Staff"}} {
+		if err = s.RoleRepository.Create(ctx, &model.RoleEntityModel{RoleEntity: role}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func testContext(global bool) *abstraction.Context {
+	orgUnitID := 1
+	return &abstraction.Context{
+		Context:  echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder()),
+		Auth:     &abstraction.AuthContext{ID: 1, OrgUnitID: &orgUnitID, Global: global},
+		TenantID: "default",
+	}
+}
+
+func TestService_checkRole(t *testing.T) {
+	s := testService(t)
+	active := true
+	create := func(ctx *abstraction.Context, username string, roleID int) (*model.UserEntityModel, error) {
+		return s.Create(ctx, &dto.UserCreateRequest{
+			Username: username,
+			Name:     username,
+			Password: "secret",
+			Email:    username + "@example.com",
+			RoleID:   roleID,
+			IsActive: &active,
+		})
+	}
+
+	if _, err := create(testContext(false), "admin", 1); response.ErrorResponse(err).Code != http.StatusForbidden {
+		t.Errorf("Create() with a global role by a non-global user error = %v, want 403", err)
+	}
+	staff, err := create(testContext(false), "staff", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Update(testContext(false), &dto.UserUpdateRequest{
+		ID:       staff.ID,
+		Username: staff.Username,
+		Name:     staff.Name,
+		Password: "secret",
+		Email:    staff.Email,
+		RoleID:   1,
+		IsActive: &active,
+	})
+	if response.ErrorResponse(err).Code != http.StatusForbidden {
+		t.Errorf("Update() to a global role by a non-global user error = %v, want 403", err)
+	}
+
+	if _, err = create(testContext(true), "root", 1); err != nil {
+		t.Errorf("Create() with a global role by a global user error = %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"slices"
 	"strconv"
@@ -17,6 +18,8 @@ type AppConfig struct {
 	Port    int
 	Version string
 	Schemes []string
+
+	BatchMaxSize int
 }
 
 var (
@@ -57,6 +60,16 @@ func App() *AppConfig {
 		if len(appConfig.Schemes) < 1 {
 			appConfig.Schemes = []string{"http"}
 		}
+
+		appConfig.BatchMaxSize = 100
+		if strBatchMaxSize, isExist := os.LookupEnv("BATCH_MAX_SIZE"); isExist && strBatchMaxSize != "" {
+			if appConfig.BatchMaxSize, err = strconv.Atoi(strBatchMaxSize); err != nil {
+				panic(err)
+			}
+			if appConfig.BatchMaxSize <= 0 {
+				panic(fmt.Errorf("BATCH_MAX_SIZE must be positive, got %d", appConfig.BatchMaxSize))
+			}
+		}
 	})
 	return appConfig
 }
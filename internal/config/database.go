@@ -1,13 +1,25 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"boilerplate/pkg/tunnel"
 	"boilerplate/pkg/util/priority"
+
+	"gorm.io/gorm/logger"
 )
 
+// DefaultConnection is the connection of the application models. Its
+// variables may also be given without the connection prefix, e.g. DB_HOST
+// instead of PGSQL_DB_BAF_DB_HOST.
+const DefaultConnection = "PGSQL_DB_BAF"
+
+// DBConfig is a node of a database connection.
 type DBConfig struct {
 	Host    string
 	Port    string
@@ -16,37 +28,249 @@ type DBConfig struct {
 	Pass    string
 	SSLMode string
 	TZ      string
+
+	// ParseTime makes the MySQL driver scan DATE and DATETIME into time.Time.
+	ParseTime bool
+}
+
+// ConnectionConfig declares a database connection, read from the variables
+// prefixed with its name, e.g. for MYSQL:
+//
+//	MYSQL_DB_DRIVER=mysql
+//	MYSQL_DB_HOST=10.0.0.3
+//	MYSQL_DB_READ_HOSTS=10.0.0.4,10.0.0.5:3307
+//	MYSQL_SSH_HOST=bastion.example.com
+type ConnectionConfig struct {
+	Name string
+	// Driver is postgres, mysql or sqlite. The DB_NAME of SQLite is the path
+	// of the database file, or :memory:.
+	Driver string
+
+	Write DBConfig
+	// Read lists the replicas serving reads made outside a transaction.
+	Read []DBConfig
+	// SSH tunnels the connections to the nodes when set, see loadSSH.
+	SSH *tunnel.Config
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectTimeout  time.Duration
+
+	// ReadMaxLag is the replication lag above which a replica stops serving
+	// reads, checked every ReadCheckInterval.
+	ReadMaxLag        time.Duration
+	ReadCheckInterval time.Duration
+	// ReadYourWritesWindow is how long the reads of a caller go to the
+	// primary after it wrote.
+	ReadYourWritesWindow time.Duration
+
+	// AutoMigrate applies the pending migrations on startup, in the local
+	// environment only.
+	AutoMigrate bool
+
+	// LogLevel is the least severe statement logged, DB_LOG_LEVEL is silent,
+	// error, warn (slow statements) or info (every statement). The local
+	// environment logs every statement by default.
+	LogLevel logger.LogLevel
+	// SlowThreshold is the duration above which a statement is logged as
+	// slow.
+	SlowThreshold time.Duration
+	// LogParams logs the parameters of the statements instead of redacting
+	// them.
+	LogParams bool
+}
+
+var logLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
 }
 
 var (
-	db     *DBConfig
-	dbOnce sync.Once
+	connections     []*ConnectionConfig
+	connectionsOnce sync.Once
 )
 
-func DB() *DBConfig {
-	dbOnce.Do(func() {
-		listSSLMode := map[string]bool{
-			"disable":     true,
-			"allow":       true,
-			"prefer":      true,
-			"require":     true,
-			"verify-ca":   true,
-			"verify-full": true,
+// Connections returns the connections listed in DB_CONNECTIONS, only the
+// default connection when unset.
+func Connections() []*ConnectionConfig {
+	connectionsOnce.Do(func() {
+		names := strings.Split(priority.PriorityString(os.Getenv("DB_CONNECTIONS"), DefaultConnection), ",")
+		for _, name := range names {
+			if name = strings.ToUpper(strings.TrimSpace(name)); name == "" {
+				continue
+			}
+			c, err := loadConnection(name)
+			if err != nil {
+				panic(err)
+			}
+			connections = append(connections, c)
 		}
-		givenSSLMode := strings.ToLower(strings.TrimSpace(priority.PriorityString(os.Getenv("DB_SSLMODE"), "disable")))
-		if _, ok := listSSLMode[givenSSLMode]; !ok {
-			givenSSLMode = "disable"
+	})
+	return connections
+}
+
+// Connection returns the connection named name, nil when it isn't declared.
+func Connection(name string) *ConnectionConfig {
+	for _, c := range Connections() {
+		if c.Name == name {
+			return c
 		}
+	}
+	return nil
+}
+
+// DB returns the default connection.
+func DB() *ConnectionConfig {
+	c := Connection(DefaultConnection)
+	if c == nil {
+		panic(fmt.Errorf("connection %s is missing from DB_CONNECTIONS", DefaultConnection))
+	}
+	return c
+}
 
-		db = &DBConfig{
-			Host:    priority.PriorityString(os.Getenv("DB_HOST"), "localhost"),
-			Port:    priority.PriorityString(os.Getenv("DB_PORT"), "5432"),
-			Name:    priority.PriorityString(os.Getenv("DB_NAME"), "db_name"),
-			User:    priority.PriorityString(os.Getenv("DB_USER"), "postgres"),
-			Pass:    priority.PriorityString(os.Getenv("DB_PASS"), "postgres"),
-			TZ:      priority.PriorityString(os.Getenv("DB_TZ"), "UTC"),
-			SSLMode: givenSSLMode,
+func loadConnection(name string) (*ConnectionConfig, error) {
+	isDefault := name == DefaultConnection
+	env := func(key string) string {
+		if v := os.Getenv(name + "_" + key); v != "" || !isDefault || !strings.HasPrefix(key, "DB_") {
+			return v
 		}
-	})
-	return db
+		return os.Getenv(key)
+	}
+
+	c := &ConnectionConfig{
+		Name:   name,
+		Driver: strings.ToLower(priority.PriorityString(env("DB_DRIVER"), "postgres")),
+
+		MaxOpenConns:    priority.PriorityInt(parseInt(env("DB_MAX_OPEN_CONNS")), 20),
+		MaxIdleConns:    priority.PriorityInt(parseInt(env("DB_MAX_IDLE_CONNS")), 5),
+		ConnMaxLifetime: parseDuration(env("DB_CONN_MAX_LIFETIME"), 5*time.Minute),
+		ConnMaxIdleTime: parseDuration(env("DB_CONN_MAX_IDLE_TIME"), time.Hour),
+		ConnectTimeout:  parseDuration(env("DB_CONNECT_TIMEOUT"), 10*time.Second),
+
+		ReadMaxLag:           parseDuration(env("DB_READ_MAX_LAG"), 5*time.Second),
+		ReadCheckInterval:    parseDuration(env("DB_READ_CHECK_INTERVAL"), 5*time.Second),
+		ReadYourWritesWindow: parseDuration(env("DB_READ_YOUR_WRITES_WINDOW"), 5*time.Second),
+		AutoMigrate:          parseBool(env("DB_AUTO_MIGRATE")),
+
+		SlowThreshold: parseDuration(env("DB_SLOW_THRESHOLD"), 200*time.Millisecond),
+		LogParams:     parseBool(env("DB_LOG_PARAMS")),
+	}
+
+	defaultLogLevel := "warn"
+	if App().IsLocal() {
+		defaultLogLevel = "info"
+	}
+	logLevel := strings.ToLower(priority.PriorityString(env("DB_LOG_LEVEL"), defaultLogLevel))
+	var ok bool
+	if c.LogLevel, ok = logLevels[logLevel]; !ok {
+		return nil, fmt.Errorf("connection %s: unknown log level %q", name, logLevel)
+	}
+
+	var defaultPort, defaultUser string
+	switch c.Driver {
+	case "postgres":
+		defaultPort, defaultUser = "5432", "postgres"
+	case "mysql":
+		defaultPort, defaultUser = "3306", "root"
+	case "sqlite":
+		// an in-memory database vanishes with the last connection of the pool
+		if env("DB_NAME") == ":memory:" {
+			c.ConnMaxLifetime = parseDuration(env("DB_CONN_MAX_LIFETIME"), 0)
+			c.ConnMaxIdleTime = parseDuration(env("DB_CONN_MAX_IDLE_TIME"), 0)
+		}
+	default:
+		return nil, fmt.Errorf("connection %s: unknown driver %q", name, c.Driver)
+	}
+
+	listSSLMode := map[string]bool{
+		"disable":     true,
+		"allow":       true,
+		"prefer":      true,
+		"require":     true,
+		"verify-ca":   true,
+		"verify-full": true,
+	}
+	givenSSLMode := strings.ToLower(strings.TrimSpace(priority.PriorityString(env("DB_SSLMODE"), "disable")))
+	if _, ok := listSSLMode[givenSSLMode]; !ok {
+		givenSSLMode = "disable"
+	}
+
+	c.Write = DBConfig{
+		Host:      env("DB_HOST"),
+		Port:      priority.PriorityString(env("DB_PORT"), defaultPort),
+		Name:      env("DB_NAME"),
+		User:      priority.PriorityString(env("DB_USER"), defaultUser),
+		Pass:      env("DB_PASS"),
+		TZ:        priority.PriorityString(env("DB_TZ"), "UTC"),
+		SSLMode:   givenSSLMode,
+		ParseTime: parseBool(env("DB_PARSE_TIME")),
+	}
+	if c.Driver == "sqlite" {
+		c.Write.Name = priority.PriorityString(c.Write.Name, strings.ToLower(name)+".db")
+		return c, nil
+	}
+	if isDefault {
+		// the defaults of a local development database
+		c.Write.Host = priority.PriorityString(c.Write.Host, "localhost")
+		c.Write.Name = priority.PriorityString(c.Write.Name, "db_name")
+		c.Write.Pass = priority.PriorityString(c.Write.Pass, "postgres")
+	}
+	if c.Write.Host == "" {
+		return nil, fmt.Errorf("connection %s: %s_DB_HOST is required", name, name)
+	}
+
+	// replicas share the credentials of the primary unless given
+	for _, host := range strings.Split(env("DB_READ_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); host == "" {
+			continue
+		}
+		replica := c.Write
+		replica.Host = host
+		replica.User = priority.PriorityString(env("DB_READ_USER"), c.Write.User)
+		replica.Pass = priority.PriorityString(env("DB_READ_PASS"), c.Write.Pass)
+		if i := strings.LastIndex(host, ":"); i > 0 {
+			replica.Host, replica.Port = host[:i], host[i+1:]
+		}
+		c.Read = append(c.Read, replica)
+	}
+
+	c.SSH = loadSSH(name + "_")
+	return c, nil
+}
+
+func parseDuration(s string, fallback time.Duration) time.Duration {
+	if s == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func parseInt(s string) int {
+	if s == "" {
+		return 0
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+func parseBool(s string) bool {
+	if s == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
 }
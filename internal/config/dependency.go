@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate/pkg/util/priority"
+)
+
+type DependencyConfig struct {
+	// StartTimeout bounds the wait for the required dependencies at startup.
+	StartTimeout time.Duration
+	// CheckInterval is how often the dependencies are checked once started,
+	// dependencies found down are dialled again with a backoff up to
+	// MaxBackoff.
+	CheckInterval time.Duration
+	MaxBackoff    time.Duration
+
+	// Optional lists the dependencies the application runs degraded without,
+	// e.g. minio, redis, db:MYSQL.
+	Optional []string
+}
+
+var (
+	dependencyConfig *DependencyConfig
+	dependencyOnce   sync.Once
+)
+
+func Dependency() *DependencyConfig {
+	dependencyOnce.Do(func() {
+		dependencyConfig = &DependencyConfig{
+			StartTimeout:  parseDuration(os.Getenv("DEPENDENCY_START_TIMEOUT"), 30*time.Second),
+			CheckInterval: parseDuration(os.Getenv("DEPENDENCY_CHECK_INTERVAL"), 10*time.Second),
+			MaxBackoff:    parseDuration(os.Getenv("DEPENDENCY_MAX_BACKOFF"), time.Minute),
+		}
+
+		for _, name := range strings.Split(priority.PriorityString(os.Getenv("DEPENDENCY_OPTIONAL"), "minio"), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				dependencyConfig.Optional = append(dependencyConfig.Optional, name)
+			}
+		}
+	})
+	return dependencyConfig
+}
+
+// IsOptional reports whether the application may run without the dependency
+// named name.
+func (c *DependencyConfig) IsOptional(name string) bool {
+	for _, optional := range c.Optional {
+		if strings.EqualFold(optional, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"boilerplate/pkg/circuitbreaker"
+	"boilerplate/pkg/tunnel"
 	"boilerplate/pkg/util/priority"
 
 	"github.com/minio/minio-go/v7"
@@ -18,6 +19,10 @@ type MinioConfig struct {
 	SecretKey   string
 	Bucket      []string
 	MinioClient *minio.Client
+	// Tunnel carries the requests to the server when MINIO_SSH_HOST is set.
+	Tunnel *tunnel.Tunnel
+	// Err is the error creating MinioClient, which is nil then.
+	Err error
 }
 
 var (
@@ -34,14 +39,19 @@ func Minio() *MinioConfig {
 		minioConfig.AccessKey = priority.PriorityString(os.Getenv("MINIO_ACCESS_KEY"))
 		minioConfig.Bucket = priority.PrioritySliceString(strings.Split(os.Getenv("MINIO_BUCKET"), ","))
 
-		var err error
-		if minioConfig.MinioClient, err = minio.New(minioConfig.Host, &minio.Options{
+		client := circuitbreaker.NewClient()
+		if ssh := loadSSH("MINIO_"); ssh != nil {
+			minioConfig.Tunnel = tunnel.New("minio", *ssh)
+			client = circuitbreaker.NewClientDialer(minioConfig.Tunnel.DialContext)
+		}
+
+		// a client that can't be created leaves the storage down, see
+		// minio.Dependencies
+		minioConfig.MinioClient, minioConfig.Err = minio.New(minioConfig.Host, &minio.Options{
 			Creds:     credentials.NewStaticV4(minioConfig.AccessKey, minioConfig.SecretKey, ""),
 			Secure:    true,
-			Transport: circuitbreaker.NewClient().Transport,
-		}); err != nil {
-			panic(err)
-		}
+			Transport: client.Transport,
+		})
 	})
 	return minioConfig
 }
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate/pkg/util/priority"
+)
+
+type OutboxConfig struct {
+	// Sinks lists the sinks events are delivered to: log, redis and webhook.
+	Sinks []string
+
+	RedisStream    string
+	RedisMaxLen    int64
+	WebhookURL     string
+	WebhookSecret  string
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	DeliverTimeout time.Duration
+
+	// err is the first malformed or out of range variable, see Validate.
+	err error
+}
+
+var (
+	outboxConfig *OutboxConfig
+	outboxOnce   sync.Once
+)
+
+func Outbox() *OutboxConfig {
+	outboxOnce.Do(func() {
+		outboxConfig = &OutboxConfig{
+			RedisStream:    priority.PriorityString(os.Getenv("OUTBOX_REDIS_STREAM"), "events"),
+			WebhookURL:     os.Getenv("OUTBOX_WEBHOOK_URL"),
+			WebhookSecret:  os.Getenv("OUTBOX_WEBHOOK_SECRET"),
+			PollInterval:   time.Second,
+			BatchSize:      100,
+			MaxAttempts:    10,
+			DeliverTimeout: 10 * time.Second,
+		}
+
+		for _, sink := range strings.Split(priority.PriorityString(os.Getenv("OUTBOX_SINKS"), "log"), ",") {
+			if sink = strings.ToLower(strings.TrimSpace(sink)); sink != "" {
+				outboxConfig.Sinks = append(outboxConfig.Sinks, sink)
+			}
+		}
+
+		var err error
+		if v := os.Getenv("OUTBOX_REDIS_MAX_LEN"); v != "" {
+			if outboxConfig.RedisMaxLen, err = strconv.ParseInt(v, 10, 64); err != nil {
+				outboxConfig.invalid("OUTBOX_REDIS_MAX_LEN", err)
+			}
+		}
+		if v := os.Getenv("OUTBOX_POLL_INTERVAL"); v != "" {
+			if outboxConfig.PollInterval, err = time.ParseDuration(v); err != nil {
+				outboxConfig.invalid("OUTBOX_POLL_INTERVAL", err)
+			} else if outboxConfig.PollInterval <= 0 {
+				outboxConfig.invalid("OUTBOX_POLL_INTERVAL", fmt.Errorf("must be positive, got %s", outboxConfig.PollInterval))
+			}
+		}
+		if v := os.Getenv("OUTBOX_BATCH_SIZE"); v != "" {
+			if outboxConfig.BatchSize, err = strconv.Atoi(v); err != nil {
+				outboxConfig.invalid("OUTBOX_BATCH_SIZE", err)
+			} else if outboxConfig.BatchSize <= 0 {
+				outboxConfig.invalid("OUTBOX_BATCH_SIZE", fmt.Errorf("must be positive, got %d", outboxConfig.BatchSize))
+			}
+		}
+		if v := os.Getenv("OUTBOX_MAX_ATTEMPTS"); v != "" {
+			if outboxConfig.MaxAttempts, err = strconv.Atoi(v); err != nil {
+				outboxConfig.invalid("OUTBOX_MAX_ATTEMPTS", err)
+			} else if outboxConfig.MaxAttempts <= 0 {
+				outboxConfig.invalid("OUTBOX_MAX_ATTEMPTS", fmt.Errorf("must be positive, got %d", outboxConfig.MaxAttempts))
+			}
+		}
+	})
+	return outboxConfig
+}
+
+// Validate reports the first malformed or out of range OUTBOX_ variable.
+func (c *OutboxConfig) Validate() error {
+	return c.err
+}
+
+func (c *OutboxConfig) invalid(name string, err error) {
+	if c.err == nil {
+		c.err = fmt.Errorf("%s: %w", name, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"sync"
 
+	"boilerplate/pkg/tunnel"
 	"boilerplate/pkg/util/priority"
 )
 
@@ -11,6 +12,8 @@ type RedisConfig struct {
 	Host string
 	Port string
 	Pass string
+	// SSH tunnels the connections to the server when set, from REDIS_SSH_*.
+	SSH *tunnel.Config
 }
 
 var (
@@ -24,6 +27,7 @@ func Redis() *RedisConfig {
 			Host: priority.PriorityString(os.Getenv("REDIS_HOST"), "localhost"),
 			Port: priority.PriorityString(os.Getenv("REDIS_PORT"), "6379"),
 			Pass: priority.PriorityString(os.Getenv("REDIS_PASSWORD"), ""),
+			SSH:  loadSSH("REDIS_"),
 		}
 	})
 	return redisConfig
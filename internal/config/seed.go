@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"boilerplate/pkg/util/priority"
+)
+
+// SeedConfig holds the bootstrap administrator created by the seed command.
+// Outside the local environment the password must be set.
+type SeedConfig struct {
+	AdminUsername string
+	AdminName     string
+	AdminEmail    string
+	AdminPassword string
+}
+
+var (
+	seedConfig *SeedConfig
+	seedOnce   sync.Once
+)
+
+func Seed() *SeedConfig {
+	seedOnce.Do(func() {
+		seedConfig = &SeedConfig{
+			AdminUsername: strings.TrimSpace(priority.PriorityString(os.Getenv("SEED_ADMIN_USERNAME"), "administrator")),
+			AdminName:     priority.PriorityString(os.Getenv("SEED_ADMIN_NAME"), "Administrator"),
+			AdminEmail:    strings.TrimSpace(priority.PriorityString(os.Getenv("SEED_ADMIN_EMAIL"), "admin@localhost")),
+			AdminPassword: os.Getenv("SEED_ADMIN_PASSWORD"),
+		}
+	})
+	return seedConfig
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"boilerplate/pkg/tunnel"
+	"boilerplate/pkg/util/priority"
+)
+
+// loadSSH reads the SSH tunnel of a service from the variables prefixed with
+// prefix, e.g. for MYSQL_:
+//
+//	MYSQL_SSH_HOST=bastion.example.com
+//	MYSQL_SSH_KEY_FILE=/run/secrets/bastion_ed25519
+//	MYSQL_SSH_HOST_KEYS=SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8
+//	MYSQL_SSH_JUMP_HOSTS=ops@gateway.example.com:2222
+//
+// It returns nil when <PREFIX>SSH_HOST is unset.
+func loadSSH(prefix string) *tunnel.Config {
+	env := func(key string) string {
+		return os.Getenv(prefix + "SSH_" + key)
+	}
+	host := env("HOST")
+	if host == "" {
+		return nil
+	}
+	return &tunnel.Config{
+		Host:                  host,
+		Port:                  priority.PriorityString(env("PORT"), "22"),
+		User:                  env("USER"),
+		Pass:                  env("PASS"),
+		KeyFile:               env("KEY_FILE"),
+		KeyPassphrase:         env("KEY_PASSPHRASE"),
+		KnownHostsFile:        env("KNOWN_HOSTS"),
+		HostKeys:              splitList(env("HOST_KEYS")),
+		InsecureIgnoreHostKey: parseBool(env("INSECURE_IGNORE_HOST_KEY")),
+		Jump:                  splitList(env("JUMP_HOSTS")),
+		KeepAliveInterval:     parseDuration(env("KEEPALIVE_INTERVAL"), 30*time.Second),
+		KeepAliveMax:          priority.PriorityInt(parseInt(env("KEEPALIVE_MAX")), 3),
+		Timeout:               parseDuration(env("TIMEOUT"), 10*time.Second),
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
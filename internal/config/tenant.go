@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"boilerplate/pkg/util/priority"
+)
+
+type TenantConfig struct {
+	// Default is the tenant of requests that don't name one, unless Required
+	// is set and they are rejected instead.
+	Default  string
+	Required bool
+
+	// Header and BaseDomain name the tenant of unauthenticated requests, e.g.
+	// X-Tenant-ID: acme or acme.<BaseDomain>. Authenticated requests use the
+	// tid claim of their token.
+	Header     string
+	BaseDomain string
+}
+
+var (
+	tenantConfig *TenantConfig
+	tenantOnce   sync.Once
+)
+
+func Tenant() *TenantConfig {
+	tenantOnce.Do(func() {
+		tenantConfig = &TenantConfig{
+			Default:    strings.ToLower(priority.PriorityString(strings.TrimSpace(os.Getenv("TENANT_DEFAULT")), "default")),
+			Header:     priority.PriorityString(os.Getenv("TENANT_HEADER"), "X-Tenant-ID"),
+			BaseDomain: strings.ToLower(strings.Trim(os.Getenv("TENANT_BASE_DOMAIN"), ". ")),
+		}
+
+		var err error
+		if v := os.Getenv("TENANT_REQUIRED"); v != "" {
+			if tenantConfig.Required, err = strconv.ParseBool(v); err != nil {
+				panic(err)
+			}
+		}
+	})
+	return tenantConfig
+}
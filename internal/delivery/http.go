@@ -4,6 +4,11 @@ import (
 	"fmt"
 	"net/http"
 
+	"boilerplate/internal/app/audit"
+	"boilerplate/internal/app/group"
+	"boilerplate/internal/app/health"
+	"boilerplate/internal/app/orgunit"
+	"boilerplate/internal/app/role"
 	"boilerplate/internal/app/user"
 	"boilerplate/internal/config"
 	"boilerplate/internal/factory"
@@ -31,6 +36,9 @@ func HTTP(e *echo.Echo, f *factory.Factory) {
 		return c.String(http.StatusOK, message)
 	})
 
+	// health
+	health.NewHandler(f).Route(e.Group("/health"))
+
 	// doc
 	_docs.SwaggerInfo.Title = APP
 	_docs.SwaggerInfo.Version = VERSION
@@ -40,12 +48,24 @@ func HTTP(e *echo.Echo, f *factory.Factory) {
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	user.NewHandler(f).Route(e.Group("/user"))
+	role.NewHandler(f).Route(e.Group("/role"))
+	group.NewHandler(f).Route(e.Group("/group"))
+	orgunit.NewHandler(f).Route(e.Group("/org-unit"))
+	// gen:route
+	audit.NewHandler(f).Route(e.Group("/audit"))
 
-	e.GET("/position", func(c echo.Context) error {
-		var data map[string]any
-		if err := database.MYSQL().WithContext(c.Request().Context()).Table("tc_positions").Limit(1).Find(&data).Error; err != nil {
-			return c.JSON(http.StatusInternalServerError, nil)
-		}
-		return c.JSON(http.StatusOK, data)
-	})
+	// only served when the MYSQL connection is declared
+	if config.Connection("MYSQL") != nil {
+		e.GET("/position", func(c echo.Context) error {
+			mysql, ok := database.Lookup("MYSQL")
+			if !ok {
+				return c.JSON(http.StatusServiceUnavailable, nil)
+			}
+			var data map[string]any
+			if err := mysql.WithContext(c.Request().Context()).Table("tc_positions").Limit(1).Find(&data).Error; err != nil {
+				return c.JSON(http.StatusInternalServerError, nil)
+			}
+			return c.JSON(http.StatusOK, data)
+		})
+	}
 }
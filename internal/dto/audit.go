@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/util/response"
+
+	"gorm.io/gorm"
+)
+
+// AuditFilter ...
+type AuditFilter struct {
+	Entity      string     `param:"entity" validate:"required"`
+	EntityID    string     `param:"id" validate:"required"`
+	Action      []string   `json:"action" query:"action" ops:"eq,ne,in,nin"`
+	ActorID     []int      `json:"actor_id" query:"actor_id" ops:"eq,ne,in,nin,null"`
+	CreatedDate *time.Time `json:"created_date" query:"-" ops:"eq,gt,gte,lt,lte"`
+
+	Conditions abstraction.Conditions `json:"-" query:"-" swaggerignore:"true"`
+}
+
+// Apply ...
+func (f AuditFilter) Apply(db *gorm.DB) *gorm.DB {
+	f.Conditions.Apply(db)
+
+	db.Where("entity = ? AND entity_id = ?", f.Entity, f.EntityID)
+	if f.Action != nil {
+		db.Where("action IN (?)", f.Action)
+	}
+	if f.ActorID != nil {
+		db.Where("actor_id IN (?)", f.ActorID)
+	}
+	return db
+}
+
+// FindAuditResponseDoc ...
+type FindAuditResponseDoc struct {
+	Meta response.Meta                `json:"meta"`
+	Data []*model.AuditLogEntityModel `json:"data"`
+}
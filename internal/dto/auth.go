@@ -48,18 +48,20 @@ func (r RefreshTokenRequest) AccessTokenClaims() (*modeltoken.AccessTokenClaims,
 		if jwtErrValidation, ok := err.(*jwt.ValidationError); ok {
 			c := token.Claims.(jwt.MapClaims)
 			return &modeltoken.AccessTokenClaims{
-				ID:     c["id"].(string),
-				RoleID: c["rid"].(string),
-				Exp:    int64(c["exp"].(float64)),
+				ID:       c["id"].(string),
+				RoleID:   c["rid"].(string),
+				TenantID: tenantClaim(c),
+				Exp:      int64(c["exp"].(float64)),
 			}, jwtErrValidation
 		}
 		return nil, jwt.NewValidationError("invalid_access_token", jwt.ValidationErrorMalformed)
 	}
 	c := token.Claims.(jwt.MapClaims)
 	return &modeltoken.AccessTokenClaims{
-		ID:     c["id"].(string),
-		RoleID: c["rid"].(string),
-		Exp:    int64(c["exp"].(float64)),
+		ID:       c["id"].(string),
+		RoleID:   c["rid"].(string),
+		TenantID: tenantClaim(c),
+		Exp:      int64(c["exp"].(float64)),
 	}, nil
 }
 
@@ -75,21 +77,29 @@ func (r RefreshTokenRequest) RefreshTokenClaims() (*modeltoken.RefreshTokenClaim
 		if jwtErrValidation, ok := err.(*jwt.ValidationError); ok {
 			c := token.Claims.(jwt.MapClaims)
 			return &modeltoken.RefreshTokenClaims{
-				ID:     c["id"].(string),
-				RoleID: c["rid"].(string),
-				Exp:    int64(c["exp"].(float64)),
+				ID:       c["id"].(string),
+				RoleID:   c["rid"].(string),
+				TenantID: tenantClaim(c),
+				Exp:      int64(c["exp"].(float64)),
 			}, jwtErrValidation
 		}
 		return nil, jwt.NewValidationError("invalid_refresh_token", jwt.ValidationErrorMalformed)
 	}
 	c := token.Claims.(jwt.MapClaims)
 	return &modeltoken.RefreshTokenClaims{
-		ID:     c["id"].(string),
-		RoleID: c["rid"].(string),
-		Exp:    int64(c["exp"].(float64)),
+		ID:       c["id"].(string),
+		RoleID:   c["rid"].(string),
+		TenantID: tenantClaim(c),
+		Exp:      int64(c["exp"].(float64)),
 	}, nil
 }
 
+// tenantClaim returns the tid claim, tokens issued before tenancy have none.
+func tenantClaim(c jwt.MapClaims) string {
+	tenantID, _ := c["tid"].(string)
+	return tenantID
+}
+
 // RefreshTokenResponse ...
 type RefreshTokenResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -0,0 +1,34 @@
+package dto
+
+import "strconv"
+
+const (
+	EventUserCreated     = "user.created"
+	EventUserDeactivated = "user.deactivated"
+)
+
+// UserCreated is published when a user is created.
+type UserCreated struct {
+	ID        int    `json:"id"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	RoleID    int    `json:"role_id"`
+	OrgUnitID *int   `json:"org_unit_id"`
+	IsActive  bool   `json:"is_active"`
+}
+
+func (UserCreated) EventType() string     { return EventUserCreated }
+func (UserCreated) AggregateType() string { return "user" }
+func (e UserCreated) AggregateID() string { return strconv.Itoa(e.ID) }
+
+// UserDeactivated is published when an active user is set inactive.
+type UserDeactivated struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (UserDeactivated) EventType() string     { return EventUserDeactivated }
+func (UserDeactivated) AggregateType() string { return "user" }
+func (e UserDeactivated) AggregateID() string { return strconv.Itoa(e.ID) }
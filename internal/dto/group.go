@@ -0,0 +1,105 @@
+package dto
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/util/response"
+
+	"gorm.io/gorm"
+)
+
+// GroupFilter ...
+type GroupFilter struct {
+	ID     []int    `json:"id" query:"id" ops:"eq,ne,in,nin"`
+	Name   []string `json:"name" query:"name" ops:"eq,ne,in,like,ilike"`
+	Type   []string `json:"type" query:"type" ops:"eq,ne,in,nin"`
+	UserID []int    `json:"user_id" query:"user_id"`
+
+	Conditions abstraction.Conditions `json:"-" query:"-" swaggerignore:"true"`
+}
+
+// Apply ...
+func (f GroupFilter) Apply(db *gorm.DB) *gorm.DB {
+	f.Conditions.Apply(db)
+
+	if f.ID != nil {
+		db.Where("id IN (?)", f.ID)
+	}
+	if f.Name != nil {
+		db.Where("name IN (?)", f.Name)
+	}
+	if f.Type != nil {
+		db.Where("type IN (?)", f.Type)
+	}
+	if f.UserID != nil {
+		db.Where("m_group.id IN (SELECT group_id FROM m_user_group WHERE user_id IN (?))", f.UserID)
+	}
+	return db
+}
+
+// SetConditions ...
+func (f *GroupFilter) SetConditions(c abstraction.Conditions) {
+	f.Conditions = c
+}
+
+// FindGroupResponseDoc ...
+type FindGroupResponseDoc struct {
+	Meta response.Meta             `json:"meta"`
+	Data []*model.GroupEntityModel `json:"data"`
+}
+
+// GroupFindByIDResponseDoc ...
+type GroupFindByIDResponseDoc struct {
+	Meta response.Meta           `json:"meta"`
+	Data *model.GroupEntityModel `json:"data"`
+}
+
+// GroupCreateRequest ...
+type GroupCreateRequest struct {
+	Name        string  `json:"name" form:"name" validate:"required,max=128" example:"Jakarta Branch"`
+	Type        string  `json:"type" form:"type" validate:"max=32" example:"branch"`
+	Description *string `json:"description" form:"description" example:"Users working at the Jakarta branch"`
+}
+
+// GroupCreateResponseDoc ...
+type GroupCreateResponseDoc struct {
+	Meta response.Meta           `json:"meta"`
+	Data *model.GroupEntityModel `json:"data"`
+}
+
+// GroupUpdateRequest ...
+type GroupUpdateRequest struct {
+	ID          int     `json:"-" param:"id" validate:"required,numeric"`
+	Name        string  `json:"name" form:"name" validate:"required,max=128" example:"Jakarta Branch"`
+	Type        string  `json:"type" form:"type" validate:"max=32" example:"branch"`
+	Description *string `json:"description" form:"description" example:"Users working at the Jakarta branch"`
+}
+
+// GroupUpdateResponseDoc ...
+type GroupUpdateResponseDoc struct {
+	Meta response.Meta           `json:"meta"`
+	Data *model.GroupEntityModel `json:"data"`
+}
+
+// GroupDeleteResponseDoc ...
+type GroupDeleteResponseDoc struct {
+	Meta response.Meta `json:"meta"`
+	Data interface{}   `json:"data"`
+}
+
+// GroupMembersRequest ...
+type GroupMembersRequest struct {
+	ID      int   `json:"-" param:"id" validate:"required,numeric"`
+	UserIDs []int `json:"user_ids" form:"user_ids" validate:"required,min=1,dive,min=1" example:"1,2,3"`
+}
+
+// GroupMembersResponse ...
+type GroupMembersResponse struct {
+	Affected int64 `json:"affected" example:"3"`
+}
+
+// GroupMembersResponseDoc ...
+type GroupMembersResponseDoc struct {
+	Meta response.Meta         `json:"meta"`
+	Data *GroupMembersResponse `json:"data"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import (
+	"boilerplate/pkg/dependency"
+	"boilerplate/pkg/util/response"
+)
+
+// HealthResponseDoc ...
+type HealthResponseDoc struct {
+	Meta response.Meta       `json:"meta"`
+	Data []dependency.Status `json:"data"`
+}
@@ -0,0 +1,98 @@
+package dto
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/util/response"
+
+	"gorm.io/gorm"
+)
+
+// OrgUnitFilter ...
+type OrgUnitFilter struct {
+	ID       []int    `json:"id" query:"id" ops:"eq,ne,in,nin"`
+	ParentID []int    `json:"parent_id" query:"parent_id" ops:"eq,ne,in,nin,null"`
+	Code     []string `json:"code" query:"code" ops:"eq,ne,in,nin"`
+	Name     []string `json:"name" query:"name" ops:"eq,ne,in,like,ilike"`
+	// AncestorID limits the result to the subtree of the org unit, itself included
+	AncestorID *int `json:"ancestor_id" query:"ancestor_id"`
+
+	Conditions abstraction.Conditions `json:"-" query:"-" swaggerignore:"true"`
+}
+
+// Apply ...
+func (f OrgUnitFilter) Apply(db *gorm.DB) *gorm.DB {
+	f.Conditions.Apply(db)
+
+	if f.ID != nil {
+		db.Where("id IN (?)", f.ID)
+	}
+	if f.ParentID != nil {
+		db.Where("parent_id IN (?)", f.ParentID)
+	}
+	if f.Code != nil {
+		db.Where("code IN (?)", f.Code)
+	}
+	if f.Name != nil {
+		db.Where("name IN (?)", f.Name)
+	}
+	if f.AncestorID != nil {
+		db.Where("m_org_unit.id IN (SELECT descendant_id FROM m_org_unit_closure WHERE ancestor_id = ?)", *f.AncestorID)
+	}
+	return db
+}
+
+// FindOrgUnitResponseDoc ...
+type FindOrgUnitResponseDoc struct {
+	Meta response.Meta               `json:"meta"`
+	Data []*model.OrgUnitEntityModel `json:"data"`
+}
+
+// OrgUnitFindByIDRequest ...
+type OrgUnitFindByIDRequest struct {
+	ID int `param:"id" validate:"required,numeric"`
+}
+
+// OrgUnitFindByIDResponseDoc ...
+type OrgUnitFindByIDResponseDoc struct {
+	Meta response.Meta             `json:"meta"`
+	Data *model.OrgUnitEntityModel `json:"data"`
+}
+
+// OrgUnitCreateRequest ...
+type OrgUnitCreateRequest struct {
+	ParentID *int   `json:"parent_id" form:"parent_id" validate:"omitempty,min=1" example:"1"`
+	Code     string `json:"code" form:"code" validate:"max=32" example:"JKT"`
+	Name     string `json:"name" form:"name" validate:"required,max=128" example:"Jakarta Branch"`
+}
+
+// OrgUnitCreateResponseDoc ...
+type OrgUnitCreateResponseDoc struct {
+	Meta response.Meta             `json:"meta"`
+	Data *model.OrgUnitEntityModel `json:"data"`
+}
+
+// OrgUnitUpdateRequest ...
+type OrgUnitUpdateRequest struct {
+	ID       int    `json:"-" param:"id" validate:"required,numeric"`
+	ParentID *int   `json:"parent_id" form:"parent_id" validate:"omitempty,min=1" example:"1"`
+	Code     string `json:"code" form:"code" validate:"max=32" example:"JKT"`
+	Name     string `json:"name" form:"name" validate:"required,max=128" example:"Jakarta Branch"`
+}
+
+// OrgUnitUpdateResponseDoc ...
+type OrgUnitUpdateResponseDoc struct {
+	Meta response.Meta             `json:"meta"`
+	Data *model.OrgUnitEntityModel `json:"data"`
+}
+
+// OrgUnitDeleteRequest ...
+type OrgUnitDeleteRequest struct {
+	ID int `param:"id" validate:"required,numeric"`
+}
+
+// OrgUnitDeleteResponseDoc ...
+type OrgUnitDeleteResponseDoc struct {
+	Meta response.Meta `json:"meta"`
+	Data interface{}   `json:"data"`
+}
@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/util/response"
+
+	"gorm.io/gorm"
+)
+
+// RoleFilter ...
+type RoleFilter struct {
+	ID   []int    `json:"id" query:"id" ops:"eq,ne,in,nin"`
+	Name []string `json:"name" query:"name" ops:"eq,ne,in,like,ilike"`
+
+	Conditions abstraction.Conditions `json:"-" query:"-" swaggerignore:"true"`
+}
+
+// Apply ...
+func (f RoleFilter) Apply(db *gorm.DB) *gorm.DB {
+	f.Conditions.Apply(db)
+
+	if f.ID != nil {
+		db.Where("id IN (?)", f.ID)
+	}
+	if f.Name != nil {
+		db.Where("name IN (?)", f.Name)
+	}
+	return db
+}
+
+// SetConditions ...
+func (f *RoleFilter) SetConditions(c abstraction.Conditions) {
+	f.Conditions = c
+}
+
+// FindRoleResponseDoc ...
+type FindRoleResponseDoc struct {
+	Meta response.Meta            `json:"meta"`
+	Data []*model.RoleEntityModel `json:"data"`
+}
+
+// RoleFindByIDResponseDoc ...
+type RoleFindByIDResponseDoc struct {
+	Meta response.Meta          `json:"meta"`
+	Data *model.RoleEntityModel `json:"data"`
+}
+
+// RoleCreateRequest ...
+type RoleCreateRequest struct {
+	Name        string  `json:"name" form:"name" validate:"required,max=64" example:"Administrator"`
+	Description *string `json:"description" form:"description" example:"Full access to the console"`
+	IsGlobal    bool    `json:"is_global" form:"is_global" example:"false"`
+}
+
+// RoleCreateResponseDoc ...
+type RoleCreateResponseDoc struct {
+	Meta response.Meta          `json:"meta"`
+	Data *model.RoleEntityModel `json:"data"`
+}
+
+// RoleUpdateRequest ...
+type RoleUpdateRequest struct {
+	ID          int     `json:"-" param:"id" validate:"required,numeric"`
+	Name        string  `json:"name" form:"name" validate:"required,max=64" example:"Administrator"`
+	Description *string `json:"description" form:"description" example:"Full access to the console"`
+	IsGlobal    bool    `json:"is_global" form:"is_global" example:"false"`
+}
+
+// RoleUpdateResponseDoc ...
+type RoleUpdateResponseDoc struct {
+	Meta response.Meta          `json:"meta"`
+	Data *model.RoleEntityModel `json:"data"`
+}
+
+// RoleDeleteResponseDoc ...
+type RoleDeleteResponseDoc struct {
+	Meta response.Meta `json:"meta"`
+	Data interface{}   `json:"data"`
+}
@@ -1,65 +1,104 @@
 package dto
 
 import (
+	"encoding/json"
 	"strings"
+	"time"
 
+	"boilerplate/internal/abstraction"
 	"boilerplate/internal/model"
+	"boilerplate/pkg/database/dialect"
 	"boilerplate/pkg/util/response"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserFilter ...
 type UserFilter struct {
-	ID       []int    `json:"id" query:"id"`
-	Name     []string `json:"name" query:"name"`
-	Username []string `json:"username" query:"username"`
-	Email    []string `json:"email" query:"email"`
-	RoleID   []int    `json:"role_id" query:"role_id"`
-	IsActive *bool    `json:"is_active" query:"is_active"`
-
+	ID           []int      `json:"id" query:"id" ops:"eq,ne,in,nin,gt,gte,lt,lte"`
+	Name         []string   `json:"name" query:"name" ops:"eq,ne,in,like,ilike"`
+	Username     []string   `json:"username" query:"username" ops:"eq,ne,in,like,ilike"`
+	Email        []string   `json:"email" query:"email" ops:"eq,ne,in,like,ilike"`
+	RoleID       []int      `json:"role_id" query:"role_id" ops:"eq,ne,in,nin"`
+	GroupID      []int      `json:"group_id" query:"group_id"`
+	OrgUnitID    []int      `json:"org_unit_id" query:"org_unit_id" ops:"eq,ne,in,nin,null"`
+	IsActive     *bool      `json:"is_active" query:"is_active" ops:"eq,ne"`
+	CreatedDate  *time.Time `json:"created_date" query:"-" ops:"eq,gt,gte,lt,lte"`
+	ModifiedDate *time.Time `json:"modified_date" query:"-" ops:"eq,gt,gte,lt,lte,null"`
+
+	// Search full-text query in web search syntax, e.g. "budi -admin"
 	Search *string `json:"search" query:"search"`
+	// Fuzzy also matches names, usernames and emails similar to search, for typos
+	Fuzzy *bool `json:"fuzzy" query:"fuzzy"`
+
+	Conditions abstraction.Conditions `json:"-" query:"-" swaggerignore:"true"`
 }
 
 // Apply ...
 func (f UserFilter) Apply(db *gorm.DB) *gorm.DB {
-	if f.Search != nil {
-		db.Where("users.email ILIKE ? OR users.username ILIKE ? OR users.name ILIKE ?", "%"+*f.Search+"%", "%"+*f.Search+"%", "%"+*f.Search+"%")
+	f.Conditions.Apply(db)
+
+	if search, ok := f.search(); ok {
+		// the trigram similarity of fuzzy search is PostgreSQL only
+		if f.fuzzy() && dialect.Name(db) == dialect.Postgres {
+			db.Where("(m_user.search_vector @@ websearch_to_tsquery('simple', ?) OR m_user.name % ? OR m_user.username % ? OR m_user.email % ?)", search, search, search, search)
+		} else {
+			db.Where(dialect.WebSearch{Column: clause.Column{Table: "m_user", Name: "search_vector"}, Query: search})
+		}
 	}
 
 	if f.ID != nil {
 		db.Where("id IN (?)", f.ID)
 	}
 	if f.Name != nil {
-		names := []string{}
-		for _, item := range f.Name {
-			names = append(names, strings.ToLower(item))
-		}
-		db.Where("LOWER(name) SIMILAR TO ?", "%("+strings.Join(names, "|")+")%")
+		db.Where(dialect.ContainsAny{Column: clause.Column{Name: "name"}, Values: f.Name})
 	}
 	if f.Username != nil {
-		usernames := []string{}
-		for _, item := range f.Username {
-			usernames = append(usernames, strings.ToLower(item))
-		}
-		db.Where("LOWER(username) SIMILAR TO ?", "%("+strings.Join(usernames, "|")+")%")
+		db.Where(dialect.ContainsAny{Column: clause.Column{Name: "username"}, Values: f.Username})
 	}
 	if f.Email != nil {
-		emails := []string{}
-		for _, item := range f.Email {
-			emails = append(emails, strings.ToLower(item))
-		}
-		db.Where("LOWER(email) SIMILAR TO ?", "%("+strings.Join(emails, "|")+")%")
+		db.Where(dialect.ContainsAny{Column: clause.Column{Name: "email"}, Values: f.Email})
 	}
 	if f.RoleID != nil {
 		db.Where("role_id IN (?)", f.RoleID)
 	}
+	if f.OrgUnitID != nil {
+		db.Where("org_unit_id IN (?)", f.OrgUnitID)
+	}
+	if f.GroupID != nil {
+		db.Where("m_user.id IN (SELECT user_id FROM m_user_group WHERE group_id IN (?))", f.GroupID)
+	}
 	if f.IsActive != nil {
 		db.Where("is_active = ?", *f.IsActive)
 	}
 	return db
 }
 
+// Relevance returns the expression ranking rows against Search, and false
+// when there is nothing to rank by or db has no full-text ranking.
+func (f UserFilter) Relevance(db *gorm.DB) (string, []interface{}, bool) {
+	search, ok := f.search()
+	if !ok || dialect.Name(db) != dialect.Postgres {
+		return "", nil, false
+	}
+	if f.fuzzy() {
+		return "ts_rank(m_user.search_vector, websearch_to_tsquery('simple', ?)) + GREATEST(similarity(m_user.name, ?), similarity(m_user.username, ?), similarity(m_user.email, ?))", []interface{}{search, search, search, search}, true
+	}
+	return "ts_rank(m_user.search_vector, websearch_to_tsquery('simple', ?))", []interface{}{search}, true
+}
+
+func (f UserFilter) search() (string, bool) {
+	if f.Search == nil || strings.TrimSpace(*f.Search) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(*f.Search), true
+}
+
+func (f UserFilter) fuzzy() bool {
+	return f.Fuzzy != nil && *f.Fuzzy
+}
+
 type FindUserResponseDoc struct {
 	Meta response.Meta            `json:"meta"`
 	Data []*model.UserEntityModel `json:"data"`
@@ -78,12 +117,14 @@ type UserFindByIDResponseDoc struct {
 
 // UserCreateRequest ...
 type UserCreateRequest struct {
-	Username string `form:"username" validate:"required" example:"administrator"`
-	Name     string `form:"name" validate:"required" example:"Lutfi Ramadhan"`
-	Password string `form:"password" validate:"required" gorm:"-" example:"nevemor3"`
-	Email    string `form:"email" validate:"required" example:"admin@console.code"`
-	RoleID   int    `form:"role_id" required:"required" example:"1"`
-	IsActive *bool  `form:"is_active" validate:"required" example:"true"`
+	Username string `json:"username" form:"username" validate:"required" example:"administrator"`
+	Name     string `json:"name" form:"name" validate:"required" example:"Lutfi Ramadhan"`
+	Password string `json:"password" form:"password" validate:"required" gorm:"-" example:"nevemor3"`
+	Email    string `json:"email" form:"email" validate:"required" example:"admin@console.code"`
+	RoleID   int    `json:"role_id" form:"role_id" required:"required" example:"1"`
+	// OrgUnitID defaults to the org unit of the caller
+	OrgUnitID *int  `json:"org_unit_id" form:"org_unit_id" validate:"omitempty,min=1" example:"1"`
+	IsActive  *bool `json:"is_active" form:"is_active" validate:"required" example:"true"`
 }
 
 // UserCreateResponseDoc ...
@@ -94,13 +135,15 @@ type UserCreateResponseDoc struct {
 
 // UserUpdateRequest ...
 type UserUpdateRequest struct {
-	ID       int    `param:"id" validate:"required,numeric"`
-	Username string `form:"username" example:"administrator"`
-	Name     string `form:"name" example:"Lutfi Ramadhan"`
-	Password string `form:"password" gorm:"-" example:"nevemor3"`
-	Email    string `form:"email" example:"admin@console.code"`
-	RoleID   int    `form:"role_id" example:"1"`
-	IsActive *bool  `form:"is_active" example:"true"`
+	ID       int    `json:"-" param:"id" validate:"required,numeric"`
+	Username string `json:"username" form:"username" example:"administrator"`
+	Name     string `json:"name" form:"name" example:"Lutfi Ramadhan"`
+	Password string `json:"password" form:"password" gorm:"-" example:"nevemor3"`
+	Email    string `json:"email" form:"email" example:"admin@console.code"`
+	RoleID   int    `json:"role_id" form:"role_id" example:"1"`
+	// OrgUnitID keeps the current org unit when empty
+	OrgUnitID *int  `json:"org_unit_id" form:"org_unit_id" validate:"omitempty,min=1" example:"1"`
+	IsActive  *bool `json:"is_active" form:"is_active" example:"true"`
 }
 
 // UserUpdateResponseDoc ...
@@ -119,3 +162,37 @@ type UserDeleteResponseDoc struct {
 	Meta response.Meta `json:"meta"`
 	Data interface{}   `json:"data"`
 }
+
+const (
+	BatchOperationCreate = "create"
+	BatchOperationUpdate = "update"
+	BatchOperationDelete = "delete"
+)
+
+// UserBatchOperation ...
+type UserBatchOperation struct {
+	Op   string          `json:"op" validate:"required,oneof=create update delete" example:"create"`
+	ID   int             `json:"id" validate:"required_unless=Op create" example:"1"`
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// UserBatchRequest ...
+type UserBatchRequest struct {
+	Atomic     bool                  `json:"atomic" example:"true"`
+	Operations []*UserBatchOperation `json:"operations" validate:"required,min=1,dive,required"`
+}
+
+// UserBatchResult ...
+type UserBatchResult struct {
+	Index  int         `json:"index" example:"0"`
+	Op     string      `json:"op" example:"create"`
+	Status int         `json:"status" example:"200"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  interface{} `json:"error,omitempty"`
+}
+
+// UserBatchResponseDoc ...
+type UserBatchResponseDoc struct {
+	Meta response.Meta      `json:"meta"`
+	Data []*UserBatchResult `json:"data"`
+}
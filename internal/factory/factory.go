@@ -1,9 +1,13 @@
 package factory
 
 import (
+	"boilerplate/internal/abstraction"
 	"boilerplate/internal/config"
 	"boilerplate/internal/repository"
+	"boilerplate/pkg/audit"
 	"boilerplate/pkg/database"
+	"boilerplate/pkg/database/replica"
+	"boilerplate/pkg/dependency"
 	"boilerplate/pkg/redis"
 
 	"github.com/minio/minio-go/v7"
@@ -14,9 +18,17 @@ import (
 type Factory struct {
 	MinioClient *minio.Client
 	RedisClient *goRedis.Client
+	// Dependencies reports the state of the connections above, set by the
+	// server.
+	Dependencies *dependency.Manager
 
-	DB             *gorm.DB
-	UserRepository repository.User
+	DB                *gorm.DB
+	UserRepository    repository.User
+	RoleRepository    repository.Role
+	GroupRepository   repository.Group
+	OrgUnitRepository repository.OrgUnit
+	AuditRepository   repository.Audit
+	// gen:repository-field
 }
 
 func NewFactory() *Factory {
@@ -29,11 +41,22 @@ func NewFactory() *Factory {
 
 func (f *Factory) SetupDB() {
 	f.DB = database.PSQL()
+	if err := abstraction.RegisterTenant(f.DB); err != nil {
+		panic(err)
+	}
+	if err := audit.Register(f.DB); err != nil {
+		panic(err)
+	}
 }
 
 func (f *Factory) SetupClient() {
 	f.RedisClient = redis.Client()
 	f.MinioClient = config.Minio().MinioClient
+
+	// route reads to the replicas, if any, keeping read-your-writes
+	if replicas := replica.FromDB(f.DB); replicas != nil {
+		abstraction.SetReadConsistency(replica.NewRouter(replicas, f.RedisClient, config.DB().ReadYourWritesWindow))
+	}
 }
 
 func (f *Factory) SetupRepository() {
@@ -42,4 +65,9 @@ func (f *Factory) SetupRepository() {
 	}
 
 	f.UserRepository = repository.NewUser(f.DB)
+	f.RoleRepository = repository.NewRole(f.DB)
+	f.GroupRepository = repository.NewGroup(f.DB)
+	f.OrgUnitRepository = repository.NewOrgUnit(f.DB)
+	f.AuditRepository = repository.NewAudit(f.DB)
+	// gen:repository
 }
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -8,12 +10,17 @@ import (
 
 	"boilerplate/internal/abstraction"
 	"boilerplate/internal/config"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/database"
 	"boilerplate/pkg/redis"
 	"boilerplate/pkg/util/aescrypt"
 	"boilerplate/pkg/util/response"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	goRedis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 func Authentication(next echo.HandlerFunc) echo.HandlerFunc {
@@ -54,8 +61,10 @@ func Authentication(next echo.HandlerFunc) echo.HandlerFunc {
 							return response.CustomErrorBuilder(http.StatusUnauthorized, response.E_UNAUTHORIZED, "invalid_token").Send(c)
 						}
 					}
-					_ = redis.Client().Del(c.Request().Context(), fmt.Sprintf("auth_user_id_%d_flag", id))
-					_ = redis.Client().Del(c.Request().Context(), fmt.Sprintf("auth_user_id_%d_info", id))
+					cc := c.(*abstraction.Context)
+					cc.TenantID = tokenTenant(token.Claims.(jwt.MapClaims))
+					_ = redis.Client().Del(c.Request().Context(), redis.Key(cc, "auth_user_id_%d_flag", id))
+					_ = redis.Client().Del(c.Request().Context(), redis.Key(cc, "auth_user_id_%d_info", id))
 					return response.CustomErrorBuilder(http.StatusUnauthorized, response.E_UNAUTHORIZED, "access_token_is_expired").Send(c)
 				}
 				return response.CustomErrorBuilder(http.StatusUnauthorized, response.E_UNAUTHORIZED, errJWT.Error()).Send(c)
@@ -95,15 +104,77 @@ func Authentication(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		cc := c.(*abstraction.Context)
+		// the token only acts on its own tenant
+		tenantID := tokenTenant(claims)
+		cc.TenantID = tenantID
+
+		scope, err := loadAuthScope(cc, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return response.CustomErrorBuilder(http.StatusUnauthorized, response.E_UNAUTHORIZED, "invalid_token").Send(c)
+			}
+			return response.ErrorBuilder(&response.ErrorConstant.InternalServerError, err).Send(c)
+		}
+		if scope.TenantID != tenantID {
+			return response.CustomErrorBuilder(http.StatusUnauthorized, response.E_UNAUTHORIZED, "invalid_token").Send(c)
+		}
+		if requested, err := requestedTenant(c); err != nil || (requested != "" && requested != tenantID) {
+			return response.CustomErrorBuilder(http.StatusForbidden, response.E_FORBIDDEN, "tenant_mismatch").Send(c)
+		}
+
 		cc.Auth = &abstraction.AuthContext{
-			ID:     id,
-			RoleID: rid,
+			ID:        id,
+			RoleID:    rid,
+			GroupIDs:  scope.GroupIDs,
+			OrgUnitID: scope.OrgUnitID,
+			Global:    scope.IsGlobal,
 		}
 
 		return next(cc)
 	}
 }
 
+// authScope is what Authentication reads of the user besides the token, cached
+// in its session, see redis.AuthScopeField. The services changing it drop the
+// cache with redis.ForgetAuthScope.
+type authScope struct {
+	GroupIDs  []int  `json:"group_ids" gorm:"-"`
+	OrgUnitID *int   `json:"org_unit_id"`
+	IsGlobal  bool   `json:"is_global"`
+	TenantID  string `json:"tenant_id"`
+}
+
+// loadAuthScope returns the scope of the user from its session, reading it
+// from the primary and caching it on a miss.
+func loadAuthScope(cc *abstraction.Context, id int) (*authScope, error) {
+	scope := new(authScope)
+	ctx := cc.Request().Context()
+	raw, err := redis.Client().HGet(ctx, redis.Key(cc, "auth_user_id_%d_info", id), redis.AuthScopeField).Bytes()
+	if err == nil && json.Unmarshal(raw, scope) == nil {
+		return scope, nil
+	}
+	if err != nil && !errors.Is(err, goRedis.Nil) {
+		logrus.WithError(err).WithField("request_id", cc.RequestID()).Warn("failed to read the cached auth scope")
+	}
+
+	scope = new(authScope)
+	if err = database.PSQL().WithContext(ctx).Model(&model.UserEntityModel{}).
+		Select("m_user.org_unit_id, COALESCE(m_role.is_global, false) AS is_global, m_user.tenant_id").
+		Joins("LEFT JOIN m_role ON m_role.id = m_user.role_id").
+		Where("m_user.id = ?", id).Take(scope).Error; err != nil {
+		return nil, err
+	}
+	if err = database.PSQL().WithContext(ctx).Model(&model.UserGroupEntityModel{}).Where("user_id = ?", id).Pluck("group_id", &scope.GroupIDs).Error; err != nil {
+		return nil, err
+	}
+
+	raw, _ = json.Marshal(scope)
+	if err = redis.SetAuthScope(cc, id, raw); err != nil {
+		logrus.WithError(err).WithField("request_id", cc.RequestID()).Warn("failed to cache the auth scope")
+	}
+	return scope, nil
+}
+
 func Logout(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var (
@@ -169,6 +240,7 @@ func Logout(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		cc := c.(*abstraction.Context)
+		cc.TenantID = tokenTenant(claims)
 		cc.Auth = &abstraction.AuthContext{
 			ID:     id,
 			RoleID: rid,
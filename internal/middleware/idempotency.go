@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/config"
+	"boilerplate/pkg/redis"
+	"boilerplate/pkg/util/aescrypt"
+	"boilerplate/pkg/util/response"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	goRedis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	idempotencyKeyMaxLen = 255
+	// idempotencyTTL is how long a response is replayed for its key.
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyLockTTL outlives the request timeout, a request holding the
+	// lock when its instance dies frees the key after it.
+	idempotencyLockTTL = 6 * time.Minute
+)
+
+// idempotentResponse is stored under an idempotency key, Status is 0 while
+// the first request is in flight.
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotency makes POST, PUT and PATCH requests carrying an Idempotency-Key
+// header safe to retry. The response of the first request is stored and
+// replayed to retries of the same request, reusing the key for a different
+// request is rejected with 422 and retries sent while the first request is in
+// flight get 409. Keys are scoped to the tenant and the user of the access
+// token, so that retries sent after a token refresh replay too. Requests
+// without a valid token run as if they had no key, anonymous clients would
+// otherwise share their keys and replay each other's responses, e.g. the
+// tokens returned by login. Server errors are not stored so that they can be
+// retried.
+func Idempotency(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		key := req.Header.Get(HeaderIdempotencyKey)
+		if key == "" || (req.Method != http.MethodPost && req.Method != http.MethodPut && req.Method != http.MethodPatch) {
+			return next(c)
+		}
+		if len(key) > idempotencyKeyMaxLen {
+			return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, "invalid_idempotency_key").Send(c)
+		}
+
+		scope, ok := idempotencyScope(req.Header.Get(echo.HeaderAuthorization))
+		if !ok {
+			return next(c)
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, "invalid_request_body").Send(c)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		var (
+			cc          = c.(*abstraction.Context)
+			ctx         = context.WithoutCancel(req.Context())
+			redisKey    = redis.Key(cc, "idempotency:%s:%s", scope, key)
+			fingerprint = digest(req.Method, req.URL.RequestURI(), string(body))
+			log         = logrus.WithFields(logrus.Fields{"request_id": cc.RequestID(), "idempotency_key": key})
+		)
+
+		lock, _ := json.Marshal(idempotentResponse{Fingerprint: fingerprint})
+		acquired, err := redis.Client().SetNX(ctx, redisKey, lock, idempotencyLockTTL).Result()
+		if err != nil {
+			// without redis the request runs as if it had no key
+			log.WithError(err).Warn("failed to lock the idempotency key")
+			return next(c)
+		}
+		if !acquired {
+			return replay(c, redisKey, fingerprint)
+		}
+
+		rec := &responseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = rec
+		err = next(c)
+		c.Response().Writer = rec.ResponseWriter
+
+		status := c.Response().Status
+		if err != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+			if errDel := redis.Client().Del(ctx, redisKey).Err(); errDel != nil {
+				log.WithError(errDel).Warn("failed to release the idempotency key")
+			}
+			return err
+		}
+
+		stored, _ := json.Marshal(idempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: c.Response().Header().Get(echo.HeaderContentType),
+			Body:        rec.body.Bytes(),
+		})
+		if errSet := redis.Client().Set(ctx, redisKey, stored, idempotencyTTL).Err(); errSet != nil {
+			log.WithError(errSet).Warn("failed to store the idempotent response")
+		}
+		return nil
+	}
+}
+
+// replay answers a request whose key is already taken.
+func replay(c echo.Context, redisKey, fingerprint string) error {
+	raw, err := redis.Client().Get(c.Request().Context(), redisKey).Bytes()
+	if errors.Is(err, goRedis.Nil) {
+		// the first request just failed and released the key
+		return inFlight(c)
+	}
+	if err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.InternalServerError, err).Send(c)
+	}
+
+	var stored idempotentResponse
+	if err = json.Unmarshal(raw, &stored); err != nil {
+		return response.ErrorBuilder(&response.ErrorConstant.InternalServerError, err).Send(c)
+	}
+	if stored.Fingerprint != fingerprint {
+		return response.CustomErrorBuilder(http.StatusUnprocessableEntity, response.E_UNPROCESSABLE_ENTITY, "idempotency_key_reused_with_another_request").Send(c)
+	}
+	if stored.Status == 0 {
+		return inFlight(c)
+	}
+
+	c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	return c.Blob(stored.Status, stored.ContentType, stored.Body)
+}
+
+func inFlight(c echo.Context) error {
+	c.Response().Header().Set("Retry-After", "1")
+	return response.CustomErrorBuilder(http.StatusConflict, response.E_CONFLICT, "idempotency_key_in_use").Send(c)
+}
+
+// idempotencyScope returns the scope of the keys sent with authToken, the
+// tenant and user of the token. ok is false without a token or when it is
+// invalid or expired, Authentication rejects the request in the latter case.
+func idempotencyScope(authToken string) (scope string, ok bool) {
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		return "", false
+	}
+
+	token, err := jwt.Parse(strings.TrimPrefix(authToken, "Bearer "), func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method :%v", token.Header["alg"])
+		}
+		return []byte(config.JWT().JWTKey), nil
+	})
+	if err != nil || token == nil || !token.Valid {
+		return "", false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["id"] == nil {
+		return "", false
+	}
+
+	// the id claim is encrypted unless it is a plain number
+	id := fmt.Sprintf("%v", claims["id"])
+	if _, err = strconv.Atoi(id); err != nil {
+		if id, err = aescrypt.DecryptAES(id, config.JWT().EncryptionKey); err != nil {
+			return "", false
+		}
+	}
+	return digest(tokenTenant(claims), id), true
+}
+
+func digest(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
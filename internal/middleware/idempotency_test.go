@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/pkg/redis"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+const testJWTKey = "test-jwt-key"
+
+func TestMain(m *testing.M) {
+	addr, err := serveRedis()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	host, port, _ := net.SplitHostPort(addr)
+	os.Setenv("REDIS_HOST", host)
+	os.Setenv("REDIS_PORT", port)
+	os.Setenv("JWT_KEY", testJWTKey)
+	redis.Init()
+	os.Exit(m.Run())
+}
+
+// serveRedis runs a server answering the GET, SET and DEL commands used by
+// Idempotency, expirations aside.
+func serveRedis() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	var (
+		mu     sync.Mutex
+		values = map[string]string{}
+	)
+	exec := func(args []string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch strings.ToUpper(args[0]) {
+		case "GET":
+			if v, ok := values[args[1]]; ok {
+				return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			}
+			return "$-1\r\n"
+		case "SET":
+			for _, opt := range args[3:] {
+				if _, exists := values[args[1]]; strings.ToUpper(opt) == "NX" && exists {
+					return "$-1\r\n"
+				}
+			}
+			values[args[1]] = args[2]
+			return "+OK\r\n"
+		case "DEL":
+			n := 0
+			for _, key := range args[1:] {
+				if _, ok := values[key]; ok {
+					delete(values, key)
+					n++
+				}
+			}
+			return fmt.Sprintf(":%d\r\n", n)
+		}
+		return "-ERR unknown command\r\n"
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					args, err := readCommand(r)
+					if err != nil {
+						return
+					}
+					if _, err = io.WriteString(conn, exec(args)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return l.Addr().String(), nil
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("unexpected %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
+
+// testKey returns a key unused so far, the server outlives the test runs.
+func testKey(t *testing.T) string {
+	return t.Name() + "-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+func testToken(t *testing.T, id int, exp time.Duration) string {
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":  id,
+		"tid": "default",
+		"exp": time.Now().Add(exp).Unix(),
+	}).SignedString([]byte(testJWTKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + s
+}
+
+// idempotentHandler counts its calls and answers them with status, once
+// release is closed when it is set.
+type idempotentHandler struct {
+	mu      sync.Mutex
+	calls   int
+	status  int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *idempotentHandler) serve(c echo.Context) error {
+	h.mu.Lock()
+	h.calls++
+	calls := h.calls
+	h.mu.Unlock()
+	if h.release != nil {
+		close(h.started)
+		<-h.release
+	}
+	return c.JSON(h.status, map[string]int{"call": calls})
+}
+
+func (h *idempotentHandler) send(key, authorization, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(HeaderIdempotencyKey, key)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	cc := &abstraction.Context{Context: echo.New().NewContext(req, rec), TenantID: "default"}
+	_ = Idempotency(h.serve)(cc)
+	return rec
+}
+
+func TestIdempotency_replay(t *testing.T) {
+	h := &idempotentHandler{status: http.StatusCreated}
+	key, token := testKey(t), testToken(t, 1, time.Minute)
+
+	first := h.send(key, token, `{"total":1}`)
+	// a token refreshed between the retries
+	retry := h.send(key, testToken(t, 1, time.Hour), `{"total":1}`)
+	if h.calls != 1 {
+		t.Fatalf("handler called %d times, want once", h.calls)
+	}
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() || retry.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("retry = %d %s, want the replayed %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+
+	// keys are scoped to the user
+	if other := h.send(key, testToken(t, 2, time.Minute), `{"total":1}`); other.Header().Get(HeaderIdempotentReplayed) != "" || h.calls != 2 {
+		t.Errorf("the key of another user replayed %s", other.Body)
+	}
+	// an expired token runs as if it had no key, Authentication rejects it
+	h.send(key, testToken(t, 1, -time.Minute), `{"total":1}`)
+	if h.calls != 3 {
+		t.Errorf("handler called %d times, want the expired token not replayed", h.calls)
+	}
+}
+
+func TestIdempotency_anonymous(t *testing.T) {
+	h := &idempotentHandler{status: http.StatusOK}
+	key := testKey(t)
+
+	// e.g. two clients logging in with the same key
+	h.send(key, "", `{"username":"a"}`)
+	if rec := h.send(key, "", `{"username":"a"}`); rec.Header().Get(HeaderIdempotentReplayed) != "" || h.calls != 2 {
+		t.Errorf("the key of an anonymous request replayed %s", rec.Body)
+	}
+}
+
+func TestIdempotency_mismatch(t *testing.T) {
+	h := &idempotentHandler{status: http.StatusCreated}
+	key, token := testKey(t), testToken(t, 1, time.Minute)
+
+	h.send(key, token, `{"total":1}`)
+	if rec := h.send(key, token, `{"total":2}`); rec.Code != http.StatusUnprocessableEntity || h.calls != 1 {
+		t.Errorf("reused key = %d after %d calls, want 422", rec.Code, h.calls)
+	}
+}
+
+func TestIdempotency_inFlight(t *testing.T) {
+	h := &idempotentHandler{status: http.StatusCreated, started: make(chan struct{}), release: make(chan struct{})}
+	key, token := testKey(t), testToken(t, 1, time.Minute)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- h.send(key, token, `{"total":1}`)
+	}()
+	<-h.started
+
+	rec := h.send(key, token, `{"total":1}`)
+	if rec.Code != http.StatusConflict || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("retry in flight = %d, want 409 with Retry-After", rec.Code)
+	}
+	close(h.release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first = %d, want 201", first.Code)
+	}
+}
+
+func TestIdempotency_serverError(t *testing.T) {
+	h := &idempotentHandler{status: http.StatusServiceUnavailable}
+	key, token := testKey(t), testToken(t, 1, time.Minute)
+
+	h.send(key, token, `{"total":1}`)
+	h.status = http.StatusCreated
+	if rec := h.send(key, token, `{"total":1}`); rec.Code != http.StatusCreated || h.calls != 2 {
+		t.Errorf("retry = %d after %d calls, want the server error retried", rec.Code, h.calls)
+	}
+}
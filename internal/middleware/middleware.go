@@ -19,9 +19,11 @@ func Init(e *echo.Echo) {
 	e.Use(Context)
 	e.Use(
 		echoMiddleware.Recover(),
+		echoMiddleware.RequestID(),
+		QueryStats,
 		echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
 			AllowOrigins: []string{"*"},
-			AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "apikey"},
+			AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, echo.HeaderXRequestID, "apikey", config.Tenant().Header, HeaderIdempotencyKey},
 			AllowMethods: []string{http.MethodGet, http.MethodPatch, http.MethodPost, http.MethodDelete},
 		}),
 		echoMiddleware.LoggerWithConfig(echoMiddleware.LoggerConfig{
@@ -34,6 +36,8 @@ func Init(e *echo.Echo) {
 			ErrorMessage: http.StatusText(http.StatusRequestTimeout),
 			Timeout:      5 * time.Minute,
 		}),
+		Tenant,
+		Idempotency,
 	)
 	e.HTTPErrorHandler = ErrorHandler
 	e.Validator = &validator.CustomValidator{Validator: validator.NewValidator()}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"boilerplate/pkg/database/querylog"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderServerTiming holds the number of statements run for the request and
+// the time spent running them.
+const HeaderServerTiming = "Server-Timing"
+
+// QueryStats counts the statements run for the request, see querylog.Stats,
+// and reports them in the Server-Timing header. It must come after the
+// RequestID middleware.
+func QueryStats(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx, stats := querylog.WithStats(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		// the statements of the handler have run once it writes the response
+		c.Response().Before(func() {
+			c.Response().Header().Add(HeaderServerTiming, stats.ServerTiming())
+		})
+		return next(c)
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/config"
+	"boilerplate/pkg/util/response"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+var errInvalidTenant = errors.New("invalid_tenant")
+
+// Tenant sets the tenant named by the request through the tenant header or a
+// subdomain of the base domain, falling back to the default tenant unless a
+// tenant is required. Authentication replaces it with the tenant of the token.
+func Tenant(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		tenantID, err := requestedTenant(c)
+		if err != nil {
+			return response.CustomErrorBuilder(http.StatusBadRequest, response.E_BAD_REQUEST, err.Error()).Send(c)
+		}
+		if tenantID == "" && !config.Tenant().Required {
+			tenantID = config.Tenant().Default
+		}
+
+		cc := c.(*abstraction.Context)
+		cc.TenantID = tenantID
+		return next(cc)
+	}
+}
+
+// requestedTenant returns the tenant the request names, if any.
+func requestedTenant(c echo.Context) (string, error) {
+	cfg := config.Tenant()
+
+	tenantID := strings.ToLower(strings.TrimSpace(c.Request().Header.Get(cfg.Header)))
+	if tenantID == "" && cfg.BaseDomain != "" {
+		host := strings.ToLower(c.Request().Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if sub, ok := strings.CutSuffix(host, "."+cfg.BaseDomain); ok && !strings.Contains(sub, ".") {
+			tenantID = sub
+		}
+	}
+	if tenantID != "" && !abstraction.ValidTenantID(tenantID) {
+		return "", errInvalidTenant
+	}
+	return tenantID, nil
+}
+
+// tokenTenant returns the tenant of a token, tokens issued before tenancy
+// belong to the default tenant.
+func tokenTenant(claims jwt.MapClaims) string {
+	if tenantID, _ := claims["tid"].(string); tenantID != "" {
+		return tenantID
+	}
+	return config.Tenant().Default
+}
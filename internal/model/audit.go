@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type AuditLogEntity struct {
+	Entity    string                 `json:"entity" example:"user"`
+	EntityID  string                 `json:"entity_id" example:"1"`
+	Action    string                 `json:"action" example:"update"`
+	Before    map[string]interface{} `json:"before" gorm:"serializer:json"`
+	After     map[string]interface{} `json:"after" gorm:"serializer:json"`
+	Changes   map[string]interface{} `json:"changes" gorm:"serializer:json"`
+	ActorID   *int                   `json:"actor_id" example:"1"`
+	RequestID string                 `json:"request_id" example:"3f2c1b7e9d"`
+	IPAddress string                 `json:"ip_address" example:"127.0.0.1"`
+	TenantID  string                 `json:"tenant_id" example:"default"`
+}
+
+// AuditLogEntityModel ...
+type AuditLogEntityModel struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement;"`
+	CreatedDate time.Time `json:"created_date" example:"1945-08-17T10:00:00Z"`
+
+	// entity
+	AuditLogEntity
+}
+
+// TableName ...
+func (AuditLogEntityModel) TableName() string {
+	return "t_audit_log"
+}
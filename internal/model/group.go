@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"boilerplate/internal/abstraction"
+
+	"gorm.io/gorm"
+)
+
+type GroupEntity struct {
+	Name        string  `json:"name" validate:"required" example:"Jakarta Branch"`
+	Type        string  `json:"type" example:"branch"`
+	Description *string `json:"description" example:"Users working at the Jakarta branch"`
+}
+
+// GroupEntityModel ...
+type GroupEntityModel struct {
+	// abstraction
+	abstraction.Entity
+	abstraction.Tenant
+
+	// entity
+	GroupEntity
+
+	// context
+	Context *abstraction.Context `json:"-" gorm:"-"`
+}
+
+// TableName ...
+func (GroupEntityModel) TableName() string {
+	return "m_group"
+}
+
+// AuditEntity ...
+func (GroupEntityModel) AuditEntity() string {
+	return "group"
+}
+
+func (m *GroupEntityModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.Context != nil && m.Context.Auth != nil {
+		m.CreatedBy = m.Context.Auth.ID
+	}
+	return m.Entity.BeforeCreate(tx)
+}
+
+func (m *GroupEntityModel) BeforeUpdate(tx *gorm.DB) (err error) {
+	if m.Context != nil && m.Context.Auth != nil {
+		m.ModifiedBy = &m.Context.Auth.ID
+	}
+	return m.Entity.BeforeUpdate(tx)
+}
+
+// UserGroupEntityModel is a membership of a user in a group.
+type UserGroupEntityModel struct {
+	UserID      int       `json:"user_id" gorm:"primaryKey"`
+	GroupID     int       `json:"group_id" gorm:"primaryKey"`
+	CreatedDate time.Time `json:"created_date" example:"1945-08-17T10:00:00Z"`
+	CreatedBy   int       `json:"created_by" example:"1"`
+}
+
+// TableName ...
+func (UserGroupEntityModel) TableName() string {
+	return "m_user_group"
+}
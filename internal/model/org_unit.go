@@ -0,0 +1,63 @@
+package model
+
+import (
+	"boilerplate/internal/abstraction"
+
+	"gorm.io/gorm"
+)
+
+type OrgUnitEntity struct {
+	ParentID *int   `json:"parent_id" example:"1"`
+	Code     string `json:"code" example:"JKT"`
+	Name     string `json:"name" validate:"required" example:"Jakarta Branch"`
+}
+
+// OrgUnitEntityModel ...
+type OrgUnitEntityModel struct {
+	// abstraction
+	abstraction.Entity
+	abstraction.Tenant
+
+	// entity
+	OrgUnitEntity
+
+	// context
+	Context *abstraction.Context `json:"-" gorm:"-"`
+}
+
+// TableName ...
+func (OrgUnitEntityModel) TableName() string {
+	return "m_org_unit"
+}
+
+// AuditEntity ...
+func (OrgUnitEntityModel) AuditEntity() string {
+	return "org_unit"
+}
+
+func (m *OrgUnitEntityModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.Context != nil && m.Context.Auth != nil {
+		m.CreatedBy = m.Context.Auth.ID
+	}
+	return m.Entity.BeforeCreate(tx)
+}
+
+func (m *OrgUnitEntityModel) BeforeUpdate(tx *gorm.DB) (err error) {
+	if m.Context != nil && m.Context.Auth != nil {
+		m.ModifiedBy = &m.Context.Auth.ID
+	}
+	return m.Entity.BeforeUpdate(tx)
+}
+
+// OrgUnitClosureEntityModel links an org unit to each of its ancestors,
+// including itself at depth 0.
+type OrgUnitClosureEntityModel struct {
+	AncestorID   int `json:"ancestor_id" gorm:"primaryKey"`
+	DescendantID int `json:"descendant_id" gorm:"primaryKey"`
+	Depth        int `json:"depth"`
+}
+
+// TableName ...
+func (OrgUnitClosureEntityModel) TableName() string {
+	return "m_org_unit_closure"
+}
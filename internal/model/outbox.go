@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusDead      = "dead"
+)
+
+type OutboxEntity struct {
+	EventID       string          `json:"event_id" example:"0b6b1c1e-8f5e-4c39-9d43-2f6f0d1f1f7a"`
+	EventType     string          `json:"event_type" example:"user.created"`
+	AggregateType string          `json:"aggregate_type" example:"user"`
+	AggregateID   string          `json:"aggregate_id" example:"1"`
+	Payload       json.RawMessage `json:"payload" gorm:"serializer:json" swaggertype:"object"`
+	ActorID       *int            `json:"actor_id" example:"1"`
+	RequestID     string          `json:"request_id" example:"3f2c1b7e9d"`
+	TenantID      string          `json:"tenant_id" example:"default"`
+
+	// delivery state
+	Status          string     `json:"status" example:"pending"`
+	Attempts        int        `json:"attempts" example:"0"`
+	NextAttemptDate time.Time  `json:"next_attempt_date" example:"1945-08-17T10:00:00Z"`
+	LastError       string     `json:"last_error" example:""`
+	DeliveredDate   *time.Time `json:"delivered_date" example:"1945-08-17T10:00:00Z"`
+}
+
+// OutboxEntityModel ...
+type OutboxEntityModel struct {
+	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	CreatedDate time.Time `json:"created_date" example:"1945-08-17T10:00:00Z"`
+
+	// entity
+	OutboxEntity
+}
+
+// TableName ...
+func (OutboxEntityModel) TableName() string {
+	return "t_outbox"
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"boilerplate/internal/abstraction"
+
+	"gorm.io/gorm"
+)
+
+type RoleEntity struct {
+	Name        string  `json:"name" validate:"required" example:"Administrator"`
+	Description *string `json:"description" example:"Full access to the console"`
+	// IsGlobal lets users with the role access every org unit
+	IsGlobal bool `json:"is_global" example:"false"`
+}
+
+// RoleEntityModel ...
+type RoleEntityModel struct {
+	// abstraction
+	abstraction.Entity
+	abstraction.Tenant
+
+	// entity
+	RoleEntity
+
+	// context
+	Context *abstraction.Context `json:"-" gorm:"-"`
+}
+
+// TableName ...
+func (RoleEntityModel) TableName() string {
+	return "m_role"
+}
+
+// AuditEntity ...
+func (RoleEntityModel) AuditEntity() string {
+	return "role"
+}
+
+func (m *RoleEntityModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.Context != nil && m.Context.Auth != nil {
+		m.CreatedBy = m.Context.Auth.ID
+	}
+	return m.Entity.BeforeCreate(tx)
+}
+
+func (m *RoleEntityModel) BeforeUpdate(tx *gorm.DB) (err error) {
+	if m.Context != nil && m.Context.Auth != nil {
+		m.ModifiedBy = &m.Context.Auth.ID
+	}
+	return m.Entity.BeforeUpdate(tx)
+}
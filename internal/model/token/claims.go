@@ -14,9 +14,10 @@ import (
 )
 
 type AccessTokenClaims struct {
-	ID     string `json:"id"`
-	RoleID string `json:"rid"`
-	Exp    int64  `json:"exp"`
+	ID       string `json:"id"`
+	RoleID   string `json:"rid"`
+	TenantID string `json:"tid,omitempty"`
+	Exp      int64  `json:"exp"`
 
 	jwt.RegisteredClaims
 }
@@ -64,16 +65,18 @@ func (c AccessTokenClaims) AuthContext() (*abstraction.AuthContext, error) {
 
 func (c AccessTokenClaims) RefreshTokenClaims() *RefreshTokenClaims {
 	return &RefreshTokenClaims{
-		ID:     c.ID,
-		RoleID: c.RoleID,
-		Exp:    time.Now().Add(config.JWT().RefreshTokenExpiry).Unix(),
+		ID:       c.ID,
+		RoleID:   c.RoleID,
+		TenantID: c.TenantID,
+		Exp:      time.Now().Add(config.JWT().RefreshTokenExpiry).Unix(),
 	}
 }
 
 type RefreshTokenClaims struct {
-	ID     string `json:"id"`
-	RoleID string `json:"rid"`
-	Exp    int64  `json:"exp"`
+	ID       string `json:"id"`
+	RoleID   string `json:"rid"`
+	TenantID string `json:"tid,omitempty"`
+	Exp      int64  `json:"exp"`
 
 	jwt.RegisteredClaims
 }
@@ -121,8 +124,9 @@ func (c RefreshTokenClaims) AuthContext() (*abstraction.AuthContext, error) {
 
 func (c RefreshTokenClaims) AccessTokenClaims() *AccessTokenClaims {
 	return &AccessTokenClaims{
-		ID:     c.ID,
-		RoleID: c.RoleID,
-		Exp:    time.Now().Add(config.JWT().AccessTokenExpiry).Unix(),
+		ID:       c.ID,
+		RoleID:   c.RoleID,
+		TenantID: c.TenantID,
+		Exp:      time.Now().Add(config.JWT().AccessTokenExpiry).Unix(),
 	}
 }
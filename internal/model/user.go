@@ -15,20 +15,32 @@ type UserEntity struct {
 	Username     string `json:"username" validate:"required" example:"administrator"`
 	Name         string `json:"name" validate:"required" example:"Lutfi Ramadhan"`
 	Password     string `json:"password" validate:"required" gorm:"-" example:"nevemor3"`
-	PasswordHash string `json:"-" gorm:"column:password"`
+	PasswordHash string `json:"-" gorm:"column:password" audit:"mask"`
 	Email        string `json:"email" validate:"required" example:"admin@console.code"`
 	RoleID       int    `json:"role_id" required:"required" example:"1"`
+	OrgUnitID    *int   `json:"org_unit_id" example:"1"`
 	IsActive     *bool  `json:"is_active" validate:"required" gorm:"default:true" example:"true"`
+
+	// generated by the database for full-text search
+	SearchVector *string `json:"-" gorm:"->" audit:"-"`
 }
 
 // UserEntityModel ...
 type UserEntityModel struct {
 	// abstraction
 	abstraction.Entity
+	abstraction.Tenant
 
 	// entity
 	UserEntity
 
+	// relations
+	Role           *RoleEntityModel    `json:"role,omitempty" gorm:"foreignKey:RoleID"`
+	Groups         []*GroupEntityModel `json:"groups,omitempty" gorm:"many2many:m_user_group;joinForeignKey:UserID;joinReferences:GroupID"`
+	OrgUnit        *OrgUnitEntityModel `json:"org_unit,omitempty" gorm:"foreignKey:OrgUnitID"`
+	CreatedByUser  *UserEntityModel    `json:"created_by_user,omitempty" gorm:"foreignKey:CreatedBy"`
+	ModifiedByUser *UserEntityModel    `json:"modified_by_user,omitempty" gorm:"foreignKey:ModifiedBy"`
+
 	// context
 	Context *abstraction.Context `json:"-" gorm:"-"`
 }
@@ -38,6 +50,11 @@ func (UserEntityModel) TableName() string {
 	return "m_user"
 }
 
+// AuditEntity ...
+func (UserEntityModel) AuditEntity() string {
+	return "user"
+}
+
 func (m *UserEntityModel) BeforeCreate(_ *gorm.DB) (err error) {
 	if m.Context != nil && m.Context.Auth != nil {
 		m.CreatedBy = m.Context.Auth.ID
@@ -70,6 +87,7 @@ func (m *UserEntityModel) GenerateToken() (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"id":  m.ID,
 		"rid": m.RoleID,
+		"tid": m.TenantID,
 		"exp": time.Now().Add(config.JWT().AccessTokenExpiry).Unix(),
 	})
 
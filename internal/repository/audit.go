@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type Audit interface {
+	Find(ctx *abstraction.Context, f *dto.AuditFilter, p *abstraction.Pagination) ([]*model.AuditLogEntityModel, *abstraction.PaginationInfo, error)
+}
+
+type audit struct {
+	abstraction.Repository
+}
+
+// AuditSortFields ...
+var AuditSortFields = abstraction.SortFields{
+	"id":           "id",
+	"action":       "action",
+	"created_date": "created_date",
+}
+
+func NewAudit(db *gorm.DB) Audit {
+	return &audit{
+		Repository: abstraction.Repository{
+			Db:         db,
+			SortFields: AuditSortFields,
+		},
+	}
+}
+
+func (r *audit) Find(ctx *abstraction.Context, f *dto.AuditFilter, p *abstraction.Pagination) ([]*model.AuditLogEntityModel, *abstraction.PaginationInfo, error) {
+	var (
+		data  []*model.AuditLogEntityModel
+		count int64
+		err   error
+
+		info = &abstraction.PaginationInfo{Pagination: p}
+	)
+
+	if p != nil {
+		if err = p.ParseSort(r.SortFields); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = r.CheckTrx(ctx).Model(&model.AuditLogEntityModel{}).Scopes(f.Apply).Count(&count).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if err = r.CheckTrx(ctx).Model(&model.AuditLogEntityModel{}).Scopes(f.Apply, func(db *gorm.DB) *gorm.DB {
+		if p != nil {
+			if p.Page == nil || p.PageSize == nil {
+				p.Init()
+			}
+			return db.Offset(p.GetOffset()).Limit(p.GetLimit()).Order(p.GetOrderBy())
+		}
+		return db
+	}).Find(&data).Error; err != nil {
+		return nil, nil, err
+	}
+
+	info.Count = count
+	return data, info, nil
+}
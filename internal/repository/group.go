@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/date"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Group interface {
+	abstraction.CRUD[model.GroupEntityModel, dto.GroupFilter]
+	FindByName(ctx *abstraction.Context, name string) (data *model.GroupEntityModel, err error)
+	AddMembers(ctx *abstraction.Context, id int, userIDs []int) *gorm.DB
+	RemoveMembers(ctx *abstraction.Context, id int, userIDs []int) *gorm.DB
+	FindMemberIDs(ctx *abstraction.Context, id int) (userIDs []int, err error)
+}
+
+type group struct {
+	abstraction.CRUDRepository[model.GroupEntityModel, dto.GroupFilter]
+}
+
+// GroupSortFields ...
+var GroupSortFields = abstraction.SortFields{
+	"id":            "id",
+	"name":          "name",
+	"type":          "type",
+	"created_date":  "created_date",
+	"modified_date": "modified_date",
+}
+
+func NewGroup(db *gorm.DB) Group {
+	return &group{
+		CRUDRepository: abstraction.CRUDRepository[model.GroupEntityModel, dto.GroupFilter]{
+			Repository: abstraction.Repository{
+				Db:         db,
+				SortFields: GroupSortFields,
+			},
+			UpdateColumns: []string{"name", "type", "description", "modified_date", "modified_by"},
+		},
+	}
+}
+
+func (r *group) FindByName(ctx *abstraction.Context, name string) (data *model.GroupEntityModel, err error) {
+	err = r.CheckTrx(ctx).Where("LOWER(name) = LOWER(?)", name).Take(&data).Error
+	return
+}
+
+// AddMembers adds the users to the group, skipping existing members.
+func (r *group) AddMembers(ctx *abstraction.Context, id int, userIDs []int) *gorm.DB {
+	var (
+		now     = *date.NowUTC()
+		members = make([]*model.UserGroupEntityModel, 0, len(userIDs))
+	)
+	for _, userID := range userIDs {
+		member := &model.UserGroupEntityModel{UserID: userID, GroupID: id, CreatedDate: now}
+		if ctx.Auth != nil {
+			member.CreatedBy = ctx.Auth.ID
+		}
+		members = append(members, member)
+	}
+	return r.CheckTrx(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&members)
+}
+
+func (r *group) RemoveMembers(ctx *abstraction.Context, id int, userIDs []int) *gorm.DB {
+	return r.CheckTrx(ctx).Where("group_id = ? AND user_id IN (?)", id, userIDs).Delete(&model.UserGroupEntityModel{})
+}
+
+func (r *group) FindMemberIDs(ctx *abstraction.Context, id int) (userIDs []int, err error) {
+	err = r.CheckTrx(ctx).Model(&model.UserGroupEntityModel{}).Where("group_id = ?", id).Pluck("user_id", &userIDs).Error
+	return
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type OrgUnit interface {
+	Find(ctx *abstraction.Context, f *dto.OrgUnitFilter, p *abstraction.Pagination) ([]*model.OrgUnitEntityModel, *abstraction.PaginationInfo, error)
+	FindByID(ctx *abstraction.Context, id int) (data *model.OrgUnitEntityModel, err error)
+	IsDescendant(ctx *abstraction.Context, ancestorID, id int) (bool, error)
+	CountChildren(ctx *abstraction.Context, id int) (count int64, err error)
+	CountUsers(ctx *abstraction.Context, id int) (count int64, err error)
+	Create(ctx *abstraction.Context, e *model.OrgUnitEntityModel) *gorm.DB
+	Update(ctx *abstraction.Context, e *model.OrgUnitEntityModel) *gorm.DB
+	Move(ctx *abstraction.Context, id int, parentID *int) error
+	Delete(ctx *abstraction.Context, id int) *gorm.DB
+}
+
+type orgUnit struct {
+	abstraction.Repository
+}
+
+// OrgUnitSortFields ...
+var OrgUnitSortFields = abstraction.SortFields{
+	"id":           "id",
+	"parent_id":    "parent_id",
+	"code":         "code",
+	"name":         "name",
+	"created_date": "created_date",
+}
+
+func NewOrgUnit(db *gorm.DB) OrgUnit {
+	return &orgUnit{
+		Repository: abstraction.Repository{
+			Db:            db,
+			SortFields:    OrgUnitSortFields,
+			OrgUnitColumn: "m_org_unit.id",
+		},
+	}
+}
+
+func (r *orgUnit) Find(ctx *abstraction.Context, f *dto.OrgUnitFilter, p *abstraction.Pagination) ([]*model.OrgUnitEntityModel, *abstraction.PaginationInfo, error) {
+	var (
+		data  []*model.OrgUnitEntityModel
+		count int64
+		err   error
+
+		info = &abstraction.PaginationInfo{Pagination: p}
+	)
+
+	if p != nil {
+		if err = p.ParseSort(r.SortFields); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = r.CheckTrx(ctx).Model(&model.OrgUnitEntityModel{}).Scopes(f.Apply).Count(&count).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if err = r.CheckTrx(ctx).Model(&model.OrgUnitEntityModel{}).Scopes(f.Apply, func(db *gorm.DB) *gorm.DB {
+		if p != nil {
+			if p.Page == nil || p.PageSize == nil {
+				p.Init()
+			}
+			return db.Offset(p.GetOffset()).Limit(p.GetLimit()).Order(p.GetOrderBy())
+		}
+		return db
+	}).Find(&data).Error; err != nil {
+		return nil, nil, err
+	}
+
+	info.Count = count
+	return data, info, nil
+}
+
+func (r *orgUnit) FindByID(ctx *abstraction.Context, id int) (data *model.OrgUnitEntityModel, err error) {
+	err = r.CheckTrx(ctx).Where("id = ?", id).Take(&data).Error
+	return
+}
+
+func (r *orgUnit) IsDescendant(ctx *abstraction.Context, ancestorID, id int) (bool, error) {
+	var count int64
+	err := r.CheckTrxUnscoped(ctx).Model(&model.OrgUnitClosureEntityModel{}).Where("ancestor_id = ? AND descendant_id = ?", ancestorID, id).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *orgUnit) CountChildren(ctx *abstraction.Context, id int) (count int64, err error) {
+	err = r.CheckTrxUnscoped(ctx).Model(&model.OrgUnitEntityModel{}).Where("parent_id = ?", id).Count(&count).Error
+	return
+}
+
+func (r *orgUnit) CountUsers(ctx *abstraction.Context, id int) (count int64, err error) {
+	err = r.CheckTrxUnscoped(ctx).Model(&model.UserEntityModel{}).Where("org_unit_id = ?", id).Count(&count).Error
+	return
+}
+
+// Create inserts the org unit and links it to the ancestors of its parent.
+func (r *orgUnit) Create(ctx *abstraction.Context, e *model.OrgUnitEntityModel) *gorm.DB {
+	db := r.CheckTrxUnscoped(ctx).Create(e)
+	if db.Error != nil {
+		return db
+	}
+	return r.CheckTrxUnscoped(ctx).Exec(`INSERT INTO m_org_unit_closure (ancestor_id, descendant_id, depth)
+		SELECT ancestor_id, CAST(? AS INTEGER), depth + 1 FROM m_org_unit_closure WHERE descendant_id = ?
+		UNION ALL SELECT CAST(? AS INTEGER), CAST(? AS INTEGER), 0`, e.ID, e.ParentID, e.ID, e.ID)
+}
+
+func (r *orgUnit) Update(ctx *abstraction.Context, e *model.OrgUnitEntityModel) *gorm.DB {
+	return r.CheckTrx(ctx).Model(e).Select("parent_id", "code", "name", "modified_date", "modified_by").Updates(e)
+}
+
+// Move relinks the subtree of id under parentID, or makes it a root when
+// parentID is nil. The caller must make sure parentID is not in the subtree.
+func (r *orgUnit) Move(ctx *abstraction.Context, id int, parentID *int) error {
+	db := r.CheckTrxUnscoped(ctx)
+	if err := db.Exec(`DELETE FROM m_org_unit_closure
+		WHERE descendant_id IN (SELECT descendant_id FROM m_org_unit_closure WHERE ancestor_id = ?)
+		AND ancestor_id NOT IN (SELECT descendant_id FROM m_org_unit_closure WHERE ancestor_id = ?)`, id, id).Error; err != nil {
+		return err
+	}
+	if parentID == nil {
+		return nil
+	}
+	return db.Exec(`INSERT INTO m_org_unit_closure (ancestor_id, descendant_id, depth)
+		SELECT p.ancestor_id, c.descendant_id, p.depth + c.depth + 1
+		FROM m_org_unit_closure p CROSS JOIN m_org_unit_closure c
+		WHERE p.descendant_id = ? AND c.ancestor_id = ?`, *parentID, id).Error
+}
+
+// Delete removes the org unit, its closure rows are removed by the foreign
+// key cascade.
+func (r *orgUnit) Delete(ctx *abstraction.Context, id int) *gorm.DB {
+	return r.CheckTrx(ctx).Where("id = ?", id).Delete(&model.OrgUnitEntityModel{})
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/database/migrations"
+	"boilerplate/pkg/database/sqlite"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// migratedDB returns an in-memory database with the schema of the
+// migrations.
+func migratedDB(t *testing.T) *gorm.DB {
+	db, err := sqlite.Config{Name: t.Name(), Path: sqlite.Memory, Logger: logger.Discard}.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, connection := range migrations.Connections() {
+		m, err := migrations.New(connection, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = m.Up(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = abstraction.RegisterTenant(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// closure returns the ancestors of id with their depth.
+func closure(t *testing.T, db *gorm.DB, id int) map[int]int {
+	var rows []*model.OrgUnitClosureEntityModel
+	if err := db.Where("descendant_id = ?", id).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	ancestors := make(map[int]int, len(rows))
+	for _, row := range rows {
+		ancestors[row.AncestorID] = row.Depth
+	}
+	return ancestors
+}
+
+func TestOrgUnit_Move(t *testing.T) {
+	db := migratedDB(t)
+	r := NewOrgUnit(db)
+	ctx := &abstraction.Context{
+		Context:  echo.New().NewContext(httptest.NewRequest("PATCH", "/", nil), httptest.NewRecorder()),
+		TenantID: "default",
+	}
+
+	// 1 ─┬─ 2 ── 3
+	//    └─ 4
+	parents := []*int{nil, intPtr(1), intPtr(2), intPtr(1)}
+	for _, parentID := range parents {
+		if err := r.Create(ctx, &model.OrgUnitEntityModel{OrgUnitEntity: model.OrgUnitEntity{ParentID: parentID, Name: "unit"}}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		parentID *int
+		want     map[int]map[int]int
+	}{
+		{
+			name:     "under a sibling",
+			parentID: intPtr(4),
+			want: map[int]map[int]int{
+				2: {2: 0, 4: 1, 1: 2},
+				3: {3: 0, 2: 1, 4: 2, 1: 3},
+				4: {4: 0, 1: 1},
+			},
+		},
+		{
+			name: "to a root",
+			want: map[int]map[int]int{
+				2: {2: 0},
+				3: {3: 0, 2: 1},
+				4: {4: 0, 1: 1},
+			},
+		},
+		{
+			name:     "back under the root",
+			parentID: intPtr(1),
+			want: map[int]map[int]int{
+				2: {2: 0, 1: 1},
+				3: {3: 0, 2: 1, 1: 2},
+				4: {4: 0, 1: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Move(ctx, 2, tt.parentID); err != nil {
+				t.Fatal(err)
+			}
+			for id, want := range tt.want {
+				if got := closure(t, db, id); !maps.Equal(got, want) {
+					t.Errorf("ancestors of %d = %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
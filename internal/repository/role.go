@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/dto"
+	"boilerplate/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type Role interface {
+	abstraction.CRUD[model.RoleEntityModel, dto.RoleFilter]
+	FindByName(ctx *abstraction.Context, name string) (data *model.RoleEntityModel, err error)
+	CountUsers(ctx *abstraction.Context, id int) (count int64, err error)
+	FindUserIDs(ctx *abstraction.Context, id int) (userIDs []int, err error)
+}
+
+type role struct {
+	abstraction.CRUDRepository[model.RoleEntityModel, dto.RoleFilter]
+}
+
+// RoleSortFields ...
+var RoleSortFields = abstraction.SortFields{
+	"id":            "id",
+	"name":          "name",
+	"created_date":  "created_date",
+	"modified_date": "modified_date",
+}
+
+func NewRole(db *gorm.DB) Role {
+	return &role{
+		CRUDRepository: abstraction.CRUDRepository[model.RoleEntityModel, dto.RoleFilter]{
+			Repository: abstraction.Repository{
+				Db:         db,
+				SortFields: RoleSortFields,
+			},
+			UpdateColumns: []string{"name", "description", "is_global", "modified_date", "modified_by"},
+		},
+	}
+}
+
+func (r *role) FindByName(ctx *abstraction.Context, name string) (data *model.RoleEntityModel, err error) {
+	err = r.CheckTrx(ctx).Where("LOWER(name) = LOWER(?)", name).Take(&data).Error
+	return
+}
+
+func (r *role) CountUsers(ctx *abstraction.Context, id int) (count int64, err error) {
+	err = r.CheckTrx(ctx).Model(&model.UserEntityModel{}).Where("role_id = ?", id).Count(&count).Error
+	return
+}
+
+func (r *role) FindUserIDs(ctx *abstraction.Context, id int) (userIDs []int, err error) {
+	err = r.CheckTrx(ctx).Model(&model.UserEntityModel{}).Where("role_id = ?", id).Pluck("id", &userIDs).Error
+	return
+}
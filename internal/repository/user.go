@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"strings"
 
 	"boilerplate/internal/abstraction"
 	"boilerplate/internal/dto"
@@ -11,9 +12,10 @@ import (
 )
 
 type User interface {
-	Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.Pagination) ([]*model.UserEntityModel, *abstraction.PaginationInfo, error)
+	Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.Pagination, fs *abstraction.Fieldset) ([]*model.UserEntityModel, *abstraction.PaginationInfo, error)
 	FindByUsernameOrEmail(ctx *abstraction.Context, username, email string) (data *model.UserEntityModel, err error)
 	FindByID(ctx *abstraction.Context, id int) (data *model.UserEntityModel, err error)
+	FindMissingIDs(ctx *abstraction.Context, ids []int) (missing []int, err error)
 	Create(ctx *abstraction.Context, e interface{}) *gorm.DB
 	Update(ctx *abstraction.Context, e *model.UserEntityModel) *gorm.DB
 	Delete(ctx *abstraction.Context, f *dto.UserFilter) *gorm.DB
@@ -23,15 +25,57 @@ type user struct {
 	abstraction.Repository
 }
 
+// UserSortFields ...
+var UserSortFields = abstraction.SortFields{
+	"id":            "id",
+	"name":          "name",
+	"username":      "username",
+	"email":         "email",
+	"role_id":       "role_id",
+	"org_unit_id":   "org_unit_id",
+	"is_active":     "is_active",
+	"created_date":  "created_date",
+	"modified_date": "modified_date",
+	"relevance":     "relevance",
+}
+
+// UserFields ...
+var UserFields = abstraction.FieldMap{
+	"id":            "id",
+	"username":      "username",
+	"name":          "name",
+	"email":         "email",
+	"role_id":       "role_id",
+	"org_unit_id":   "org_unit_id",
+	"is_active":     "is_active",
+	"created_date":  "created_date",
+	"created_by":    "created_by",
+	"modified_date": "modified_date",
+	"modified_by":   "modified_by",
+}
+
+// UserExpansions ...
+var UserExpansions = abstraction.Expansions{
+	"role":             {Relation: "Role", Columns: []string{"role_id"}},
+	"groups":           {Relation: "Groups"},
+	"org_unit":         {Relation: "OrgUnit", Columns: []string{"org_unit_id"}},
+	"created_by_user":  {Relation: "CreatedByUser", Columns: []string{"created_by"}},
+	"modified_by_user": {Relation: "ModifiedByUser", Columns: []string{"modified_by"}},
+}
+
 func NewUser(db *gorm.DB) User {
 	return &user{
 		Repository: abstraction.Repository{
-			Db: db,
+			Db:            db,
+			SortFields:    UserSortFields,
+			Fields:        UserFields,
+			Expansions:    UserExpansions,
+			OrgUnitColumn: "m_user.org_unit_id",
 		},
 	}
 }
 
-func (r *user) Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.Pagination) ([]*model.UserEntityModel, *abstraction.PaginationInfo, error) {
+func (r *user) Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.Pagination, fs *abstraction.Fieldset) ([]*model.UserEntityModel, *abstraction.PaginationInfo, error) {
 	var (
 		data  []*model.UserEntityModel
 		count int64
@@ -40,6 +84,68 @@ func (r *user) Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.
 		info = &abstraction.PaginationInfo{Pagination: p}
 	)
 
+	// relevance is a computed column, only available when searching and not
+	// usable as a keyset column
+	relevance, relevanceVars, ranked := "", []interface{}(nil), false
+	if f != nil {
+		relevance, relevanceVars, ranked = f.Relevance(r.Db)
+	}
+	sortFields := r.SortFields
+	if !ranked || p.IsCursor() {
+		sortFields = abstraction.SortFields{}
+		for name, column := range r.SortFields {
+			if name != "relevance" {
+				sortFields[name] = column
+			}
+		}
+	}
+
+	if p != nil {
+		if ranked && !p.IsCursor() && p.Sort == nil && p.OrderBy == nil {
+			sort := "-relevance"
+			p.Sort = &sort
+		}
+		if err = p.ParseSort(sortFields); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if fs != nil {
+		if err = fs.ParseFieldset(r.Fields, r.Expansions); err != nil {
+			return nil, nil, err
+		}
+		if p.IsCursor() {
+			fs.Require(p.SortColumns()...)
+		}
+	}
+	project := func(db *gorm.DB) *gorm.DB {
+		if ranked {
+			columns := "m_user.*"
+			if fs.Columns() != nil {
+				columns = strings.Join(fs.Columns(), ", ")
+			}
+			db.Select(columns+", "+relevance+" AS relevance", relevanceVars...)
+		} else if fs.Columns() != nil {
+			db.Select(fs.Columns())
+		}
+		return fs.Preload(db)
+	}
+
+	if p.IsCursor() {
+		if err = r.CheckTrx(ctx).Model(&model.UserEntityModel{}).Scopes(func(db *gorm.DB) *gorm.DB {
+			if f != nil {
+				f.Apply(db)
+			}
+			return db
+		}, project, p.ApplyCursor).Find(&data).Error; err != nil {
+			return nil, nil, err
+		}
+		if info, err = p.SetCursorInfo(r.Db, &data); err != nil {
+			return nil, nil, err
+		}
+		return data, info, nil
+	}
+
 	if err = r.CheckTrx(ctx).Model(&model.UserEntityModel{}).Scopes(func(db *gorm.DB) *gorm.DB {
 		if f != nil {
 			f.Apply(db)
@@ -53,6 +159,7 @@ func (r *user) Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.
 		if f != nil {
 			f.Apply(db)
 		}
+		project(db)
 		if p != nil {
 			if p.Page == nil || p.PageSize == nil {
 				p.Init()
@@ -69,12 +176,12 @@ func (r *user) Find(ctx *abstraction.Context, f *dto.UserFilter, p *abstraction.
 }
 
 func (r *user) FindByUsernameOrEmail(ctx *abstraction.Context, username, email string) (data *model.UserEntityModel, err error) {
-	err = r.CheckTrx(ctx).Where("username = ? OR email = ?", username, email).Take(&data).Error
+	err = r.CheckTrxUnscoped(ctx).Where("username = ? OR email = ?", username, email).Take(&data).Error
 	return
 }
 
 func (r *user) FindByID(ctx *abstraction.Context, id int) (data *model.UserEntityModel, err error) {
-	err = r.CheckTrx(ctx).Where("id = ?", id).Take(&data).Error
+	err = r.CheckTrx(ctx).Preload("Role").Where("id = ?", id).Take(&data).Error
 	return
 }
 
@@ -94,3 +201,23 @@ func (r *user) Delete(ctx *abstraction.Context, f *dto.UserFilter) *gorm.DB {
 		return f.Apply(db)
 	}).Delete(&model.UserEntityModel{})
 }
+
+// FindMissingIDs returns the ids of ids that aren't users the caller can see,
+// i.e. of other tenants or outside the caller's org unit subtree.
+func (r *user) FindMissingIDs(ctx *abstraction.Context, ids []int) (missing []int, err error) {
+	var found []int
+	if err = r.CheckTrx(ctx).Model(&model.UserEntityModel{}).Where("m_user.id IN (?)", ids).Pluck("m_user.id", &found).Error; err != nil {
+		return nil, err
+	}
+	exists := make(map[int]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	for _, id := range ids {
+		if !exists[id] {
+			missing = append(missing, id)
+			exists[id] = true
+		}
+	}
+	return missing, nil
+}
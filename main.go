@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -15,6 +16,9 @@ import (
 	"boilerplate/internal/factory"
 	"boilerplate/internal/middleware"
 	"boilerplate/pkg/database"
+	"boilerplate/pkg/dependency"
+	"boilerplate/pkg/minio"
+	"boilerplate/pkg/outbox"
 	"boilerplate/pkg/redis"
 
 	"github.com/joho/godotenv"
@@ -27,6 +31,12 @@ func init() {
 	logrus.Info("Choosen Environment : ", os.Getenv("ENV"))
 }
 
+// commands are the subcommands of the binary, without one it serves the API.
+var commands = map[string]func(args []string) error{
+	"migrate": runMigrate,
+	"seed":    runSeed,
+}
+
 // @title BAF QRCode API CMS
 // @version 0.1.0
 // @description This is a doc for baf-qrcode-api-cms.
@@ -38,20 +48,62 @@ func init() {
 // @in header
 // @name Authorization
 func main() {
-	PORT := fmt.Sprintf("%d", config.App().Port)
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 
-	database.Init()
-	defer database.Close()
+	PORT := fmt.Sprintf("%d", config.App().Port)
 
+	// wait for the dependencies, the optional ones may come up later
 	redis.Init()
 	defer redis.Close()
 
+	depConfig := config.Dependency()
+	deps := dependency.NewManager(depConfig.StartTimeout, depConfig.CheckInterval, depConfig.MaxBackoff)
+	deps.Add(database.Dependencies()...)
+	deps.Add(redis.Dependencies()...)
+	deps.Add(minio.Dependencies()...)
+	defer database.Close()
+	if err := deps.Start(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+
+	if config.App().IsLocal() {
+		if err := autoMigrate(); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
 	e := echo.New()
 	f := factory.NewFactory()
+	f.Dependencies = deps
 
 	middleware.Init(e)
 	delivery.HTTP(e, f)
 
+	// Start background workers
+	sinks, err := outbox.Sinks(f.RedisClient)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		outbox.NewDispatcher(f.DB, sinks...).Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		deps.Run(workerCtx)
+	}()
+
 	// Start server
 	go func() {
 		if err := e.Start(":" + PORT); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -74,4 +126,7 @@ func main() {
 	if err := e.Shutdown(ctx); err != nil {
 		e.Logger.Fatal(err)
 	}
+
+	stopWorkers()
+	workers.Wait()
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"boilerplate/internal/config"
+	"boilerplate/pkg/database"
+	"boilerplate/pkg/database/migrations"
+)
+
+const migrateUsage = `usage: migrate <command> [flags]
+
+commands:
+  up [--steps N]            apply the pending migrations, N of them if given
+  down [--steps N | --all]  revert the last N migrations, 1 by default
+  status                    list the migrations and whether they are applied
+  create <name>             add empty up and down files for a new migration
+
+flags:
+  --connection NAME         database connection, PGSQL_DB_BAF by default
+`
+
+// runMigrate runs the migrate subcommand with the arguments following it.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return fmt.Errorf("missing command")
+	}
+
+	var (
+		command    = args[0]
+		connection string
+		steps      int
+		all        bool
+		root       string
+	)
+	fs := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	fs.StringVar(&connection, "connection", config.DefaultConnection, "database connection")
+	fs.IntVar(&steps, "steps", 0, "number of migrations")
+	fs.BoolVar(&all, "all", false, "revert every migration")
+	fs.StringVar(&root, "root", ".", "project root, for create")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+
+	// accept flags both before and after the migration name
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	var name string
+	if command == "create" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %s", strings.Join(fs.Args(), " "))
+	}
+	connection = strings.ToUpper(connection)
+
+	switch command {
+	case "create":
+		if name == "" {
+			return fmt.Errorf("missing migration name")
+		}
+		paths, err := migrations.Create(filepath.Join(root, "pkg", "database", "migrations"), connection, name, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			fmt.Println("created", p)
+		}
+		return nil
+	case "up", "down", "status":
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+	if steps < 0 {
+		return fmt.Errorf("--steps must be positive")
+	}
+	if command == "down" && !all && steps == 0 {
+		steps = 1
+	}
+
+	defer database.Close()
+	if err := database.Init(); err != nil {
+		return err
+	}
+
+	db, ok := database.Lookup(connection)
+	if !ok {
+		return fmt.Errorf("connection %s is not declared in DB_CONNECTIONS", connection)
+	}
+	m, err := migrations.New(connection, db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		done, err := m.Up(ctx, steps)
+		printMigrations("applied", done, err)
+		return err
+	case "down":
+		done, err := m.Down(ctx, steps)
+		printMigrations("reverted", done, err)
+		return err
+	default:
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+		for _, s := range status {
+			appliedAt, note := "pending", ""
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			switch {
+			case s.Missing:
+				note = "file missing"
+			case s.Modified:
+				note = "modified after it was applied"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, note)
+		}
+		return w.Flush()
+	}
+}
+
+func printMigrations(verb string, done []*migrations.Migration, err error) {
+	for _, mig := range done {
+		fmt.Printf("%s %d_%s\n", verb, mig.Version, mig.Name)
+	}
+	if len(done) == 0 && err == nil {
+		fmt.Println("nothing to do")
+	}
+}
+
+// autoMigrate applies the pending migrations of the connections with
+// AutoMigrate set, for local development.
+func autoMigrate() error {
+	for _, c := range config.Connections() {
+		if !c.AutoMigrate || !slices.Contains(migrations.Connections(), c.Name) {
+			continue
+		}
+		m, err := migrations.New(c.Name, database.Connection(c.Name))
+		if err != nil {
+			return err
+		}
+		if _, err = m.Up(context.Background(), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package audit
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	masked    = "******"
+	beforeKey = "audit:before"
+)
+
+// Register adds the audit callbacks to db. Only models implementing
+// abstraction.Auditable are recorded. Fields tagged `audit:"-"` are left out
+// of the log and fields tagged `audit:"mask"` are recorded as masked values.
+func Register(db *gorm.DB) error {
+	cb := db.Callback()
+	if cb.Create().Get("audit:after_create") != nil {
+		return nil
+	}
+	if err := cb.Create().After("gorm:create").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("audit:before_update", capture); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("audit:after_update", afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("audit:before_delete", capture); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("audit:after_delete", afterDelete)
+}
+
+func entity(tx *gorm.DB) (string, bool) {
+	if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.Schema.PrioritizedPrimaryField == nil {
+		return "", false
+	}
+	a, ok := reflect.New(tx.Statement.Schema.ModelType).Interface().(abstraction.Auditable)
+	if !ok {
+		return "", false
+	}
+	return a.AuditEntity(), true
+}
+
+// capture stores the rows targeted by an update or delete before they change.
+func capture(tx *gorm.DB) {
+	if _, ok := entity(tx); !ok {
+		return
+	}
+	exprs := conditions(tx.Statement)
+	if len(exprs) == 0 {
+		return
+	}
+	var rows []map[string]interface{}
+	if err := session(tx).Clauses(clause.Where{Exprs: exprs}).Find(&rows).Error; err != nil {
+		_ = tx.AddError(err)
+		return
+	}
+	tx.InstanceSet(beforeKey, rows)
+}
+
+func afterCreate(tx *gorm.DB) {
+	name, ok := entity(tx)
+	if !ok {
+		return
+	}
+	var (
+		pk  = tx.Statement.Schema.PrioritizedPrimaryField
+		ids []interface{}
+	)
+	switch tx.Statement.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < tx.Statement.ReflectValue.Len(); i++ {
+			if v, zero := pk.ValueOf(tx.Statement.Context, reflect.Indirect(tx.Statement.ReflectValue.Index(i))); !zero {
+				ids = append(ids, v)
+			}
+		}
+	case reflect.Struct:
+		if v, zero := pk.ValueOf(tx.Statement.Context, tx.Statement.ReflectValue); !zero {
+			ids = append(ids, v)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	after, err := reload(tx, ids)
+	if err != nil {
+		_ = tx.AddError(err)
+		return
+	}
+	write(tx, name, ActionCreate, nil, after)
+}
+
+func afterUpdate(tx *gorm.DB) {
+	name, ok := entity(tx)
+	if !ok {
+		return
+	}
+	before := captured(tx)
+	if len(before) == 0 {
+		return
+	}
+	var ids []interface{}
+	for _, row := range before {
+		ids = append(ids, row[tx.Statement.Schema.PrioritizedPrimaryField.DBName])
+	}
+	after, err := reload(tx, ids)
+	if err != nil {
+		_ = tx.AddError(err)
+		return
+	}
+	write(tx, name, ActionUpdate, before, after)
+}
+
+func afterDelete(tx *gorm.DB) {
+	name, ok := entity(tx)
+	if !ok {
+		return
+	}
+	if before := captured(tx); len(before) > 0 {
+		write(tx, name, ActionDelete, before, nil)
+	}
+}
+
+func captured(tx *gorm.DB) []map[string]interface{} {
+	if v, ok := tx.InstanceGet(beforeKey); ok {
+		rows, _ := v.([]map[string]interface{})
+		return rows
+	}
+	return nil
+}
+
+// conditions mirrors the WHERE clause GORM is about to build, including the
+// primary key of the model value that Save/Delete add on their own.
+func conditions(stmt *gorm.Statement) (exprs []clause.Expression) {
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs = append(exprs, where.Exprs...)
+		}
+	}
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		pk := stmt.Schema.PrioritizedPrimaryField
+		if v, zero := pk.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+			exprs = append(exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: v})
+		}
+	}
+	return
+}
+
+func session(tx *gorm.DB) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(tx.Statement.Table)
+}
+
+func reload(tx *gorm.DB, ids []interface{}) (rows []map[string]interface{}, err error) {
+	pk := tx.Statement.Schema.PrioritizedPrimaryField.DBName
+	err = session(tx).Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: pk}, Values: ids}).Find(&rows).Error
+	return
+}
+
+func write(tx *gorm.DB, name, action string, before, after []map[string]interface{}) {
+	var (
+		pk      = tx.Statement.Schema.PrioritizedPrimaryField.DBName
+		now     = time.Now().UTC()
+		entries []*model.AuditLogEntityModel
+	)
+
+	index := func(rows []map[string]interface{}) map[string]map[string]interface{} {
+		m := make(map[string]map[string]interface{}, len(rows))
+		for _, row := range rows {
+			m[fmt.Sprint(row[pk])] = row
+		}
+		return m
+	}
+	beforeByID, afterByID := index(before), index(after)
+
+	ids := make([]string, 0, len(beforeByID)+len(afterByID))
+	for _, row := range before {
+		ids = append(ids, fmt.Sprint(row[pk]))
+	}
+	for _, row := range after {
+		if _, ok := beforeByID[fmt.Sprint(row[pk])]; !ok {
+			ids = append(ids, fmt.Sprint(row[pk]))
+		}
+	}
+
+	for _, id := range ids {
+		b, a := beforeByID[id], afterByID[id]
+		changes := diff(b, a)
+		if action == ActionUpdate && len(changes) == 0 {
+			continue
+		}
+		entry := &model.AuditLogEntityModel{CreatedDate: now}
+		entry.Entity = name
+		entry.EntityID = id
+		entry.Action = action
+		entry.Changes = changes
+		entry.Before = b
+		entry.After = a
+		actor(tx, &entry.AuditLogEntity)
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	for _, field := range tx.Statement.Schema.Fields {
+		switch field.Tag.Get("audit") {
+		case "-":
+			for _, e := range entries {
+				omit(e, field)
+			}
+		case "mask":
+			for _, e := range entries {
+				mask(e, field)
+			}
+		}
+	}
+
+	if err := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Create(&entries).Error; err != nil {
+		_ = tx.AddError(err)
+	}
+}
+
+func actor(tx *gorm.DB, e *model.AuditLogEntity) {
+	c, ok := abstraction.FromContext(tx.Statement.Context)
+	if !ok {
+		return
+	}
+	if c.Auth != nil {
+		id := c.Auth.ID
+		e.ActorID = &id
+	}
+	e.RequestID = c.RequestID()
+	e.IPAddress = c.RealIP()
+	e.TenantID = c.TenantID
+}
+
+func diff(before, after map[string]interface{}) map[string]interface{} {
+	changes := make(map[string]interface{})
+	for k, a := range after {
+		b, ok := before[k]
+		if (!ok && a == nil) || (ok && fmt.Sprint(b) == fmt.Sprint(a)) {
+			continue
+		}
+		changes[k] = map[string]interface{}{"from": b, "to": a}
+	}
+	for k, b := range before {
+		if _, ok := after[k]; !ok && after != nil {
+			changes[k] = map[string]interface{}{"from": b, "to": nil}
+		}
+	}
+	return changes
+}
+
+func omit(e *model.AuditLogEntityModel, field *schema.Field) {
+	delete(e.Before, field.DBName)
+	delete(e.After, field.DBName)
+	delete(e.Changes, field.DBName)
+}
+
+func mask(e *model.AuditLogEntityModel, field *schema.Field) {
+	for _, row := range []map[string]interface{}{e.Before, e.After} {
+		if v, ok := row[field.DBName]; ok && v != nil {
+			row[field.DBName] = masked
+		}
+	}
+	if _, ok := e.Changes[field.DBName]; ok {
+		e.Changes[field.DBName] = map[string]interface{}{"from": masked, "to": masked}
+	}
+}
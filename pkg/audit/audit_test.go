@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/database/migrations"
+	"boilerplate/pkg/database/sqlite"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type auditRow struct {
+	ID       int
+	Name     string
+	Secret   string `audit:"mask"`
+	Internal string `audit:"-"`
+}
+
+func (auditRow) AuditEntity() string { return "row" }
+
+// plainRow isn't Auditable.
+type plainRow struct {
+	ID   int
+	Name string
+}
+
+// auditDB returns a migrated database recording the changes of auditRow,
+// opened with the request context of actor 7 of tenant t1.
+func auditDB(t *testing.T) (db, request *gorm.DB) {
+	db, err := sqlite.Config{Name: t.Name(), Path: sqlite.Memory, Logger: logger.Discard}.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, connection := range migrations.Connections() {
+		m, err := migrations.New(connection, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = m.Up(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = db.AutoMigrate(&auditRow{}, &plainRow{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = Register(db); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	ctx := &abstraction.Context{
+		Context:  echo.New().NewContext(req, httptest.NewRecorder()),
+		Auth:     &abstraction.AuthContext{ID: 7},
+		TenantID: "t1",
+	}
+	return db, db.WithContext(ctx.RequestContext())
+}
+
+func auditLogs(t *testing.T, db *gorm.DB, action string) []*model.AuditLogEntityModel {
+	var rows []*model.AuditLogEntityModel
+	if err := db.Where("action = ?", action).Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+// change returns the from and to values of column in e, formatted.
+func change(e *model.AuditLogEntityModel, column string) string {
+	c, ok := e.Changes[column].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(c["from"], " -> ", c["to"])
+}
+
+func TestRegister_create(t *testing.T) {
+	db, request := auditDB(t)
+
+	rows := []*auditRow{{Name: "a", Secret: "s", Internal: "i"}, {Name: "b"}}
+	if err := request.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := request.Create(&plainRow{Name: "p"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := auditLogs(t, db, ActionCreate)
+	if len(logs) != 2 {
+		t.Fatalf("logs = %d, want one per auditRow", len(logs))
+	}
+	e := logs[0]
+	if e.Entity != "row" || e.EntityID != "1" || len(e.Before) != 0 {
+		t.Errorf("log = %+v, want the creation of row 1", e.AuditLogEntity)
+	}
+	if e.ActorID == nil || *e.ActorID != 7 || e.TenantID != "t1" || e.RequestID != "req-1" || e.IPAddress != "192.0.2.1" {
+		t.Errorf("log = %+v, want stamped with actor 7 of tenant t1", e.AuditLogEntity)
+	}
+	if e.After["name"] != "a" || e.After["secret"] != masked || change(e, "name") != "<nil> -> a" || change(e, "secret") != masked+" -> "+masked {
+		t.Errorf("after = %v, changes = %v, want the name and the masked secret", e.After, e.Changes)
+	}
+	if _, ok := e.After["internal"]; ok {
+		t.Errorf("after = %v, want internal omitted", e.After)
+	}
+	if _, ok := e.Changes["internal"]; ok {
+		t.Errorf("changes = %v, want internal omitted", e.Changes)
+	}
+
+	// without a request nobody is stamped
+	if err := db.Create(&auditRow{Name: "c"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if e = auditLogs(t, db, ActionCreate)[2]; e.ActorID != nil || e.TenantID != "" || e.RequestID != "" {
+		t.Errorf("log = %+v, want no actor", e.AuditLogEntity)
+	}
+}
+
+func TestRegister_update(t *testing.T) {
+	db, request := auditDB(t)
+	rows := []*auditRow{{Name: "a", Secret: "s", Internal: "i"}, {Name: "b"}, {Name: "c"}}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := request.Model(&auditRow{ID: 1}).Updates(&auditRow{Name: "a2", Secret: "s2", Internal: "i2"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// unchanged rows aren't logged
+	if err := request.Model(&auditRow{}).Where("name IN ?", []string{"b", "c"}).Update("name", "c").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := auditLogs(t, db, ActionUpdate)
+	if len(logs) != 2 || logs[0].EntityID != "1" || logs[1].EntityID != "2" {
+		t.Fatalf("logs = %d, want rows 1 and 2", len(logs))
+	}
+	e := logs[0]
+	if len(e.Changes) != 2 || change(e, "name") != "a -> a2" || change(e, "secret") != masked+" -> "+masked {
+		t.Errorf("changes = %v, want the name and the masked secret", e.Changes)
+	}
+	if e.Before["secret"] != masked || e.After["secret"] != masked || e.Before["name"] != "a" || e.After["name"] != "a2" {
+		t.Errorf("before = %v, after = %v", e.Before, e.After)
+	}
+	if _, ok := e.Before["internal"]; ok {
+		t.Errorf("before = %v, want internal omitted", e.Before)
+	}
+	if e = logs[1]; len(e.Changes) != 1 || change(e, "name") != "b -> c" {
+		t.Errorf("changes = %v, want the name of row 2 only", e.Changes)
+	}
+}
+
+func TestRegister_delete(t *testing.T) {
+	db, request := auditDB(t)
+	if err := db.Create(&[]*auditRow{{Name: "a", Secret: "s"}, {Name: "b"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := request.Delete(&auditRow{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := auditLogs(t, db, ActionDelete)
+	if len(logs) != 1 {
+		t.Fatalf("logs = %d, want row 1 only", len(logs))
+	}
+	e := logs[0]
+	if e.EntityID != "1" || len(e.After) != 0 || e.Before["name"] != "a" || e.Before["secret"] != masked {
+		t.Errorf("log = %+v, want the deletion of row 1", e.AuditLogEntity)
+	}
+	if e.ActorID == nil || *e.ActorID != 7 || e.TenantID != "t1" {
+		t.Errorf("log = %+v, want stamped with actor 7 of tenant t1", e.AuditLogEntity)
+	}
+}
@@ -1,6 +1,8 @@
 package circuitbreaker
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"time"
 
@@ -18,12 +20,18 @@ type (
 
 // NewClient ...
 func NewClient() *http.Client {
-	return newClient()
+	return newClient(nil)
 }
 
-func newClient() *http.Client {
+// NewClientDialer returns a client opening its connections with dial, e.g.
+// through an SSH tunnel.
+func NewClientDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) *http.Client {
+	return newClient(dial)
+}
+
+func newClient(dial func(ctx context.Context, network, addr string) (net.Conn, error)) *http.Client {
 	return &http.Client{
-		Transport: newTransport(newCircuitBreaker()),
+		Transport: newTransport(newCircuitBreaker(), dial),
 	}
 }
 
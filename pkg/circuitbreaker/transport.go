@@ -1,6 +1,7 @@
 package circuitbreaker
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -15,12 +16,15 @@ type (
 	}
 )
 
-func newTransport(cb Breaker) *Transport {
-	t := &http.Transport{
-		DialContext: (&net.Dialer{
+func newTransport(cb Breaker, dial func(ctx context.Context, network, addr string) (net.Conn, error)) *Transport {
+	if dial == nil {
+		dial = (&net.Dialer{
 			Timeout:   10 * time.Second,
 			KeepAlive: 90 * time.Second,
-		}).DialContext,
+		}).DialContext
+	}
+	t := &http.Transport{
+		DialContext: dial,
 	}
 
 	return &Transport{
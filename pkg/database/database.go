@@ -1,25 +1,28 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"net"
-	"os"
-	"time"
+	"sync"
 
 	"boilerplate/internal/config"
 	"boilerplate/pkg/database/msql"
 	"boilerplate/pkg/database/psql"
+	"boilerplate/pkg/database/querylog"
+	"boilerplate/pkg/database/replica"
+	"boilerplate/pkg/database/sqlite"
+	"boilerplate/pkg/dependency"
+	"boilerplate/pkg/tunnel"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/ssh"
 	"gorm.io/gorm"
 )
 
 var (
 	err           error
-	netConnection map[string]net.Conn
-	sshConnection map[string]*ssh.Client
+	mu            sync.RWMutex
+	tunnels       map[string]*tunnel.Tunnel
 	dbConnections map[string]*gorm.DB
 )
 
@@ -29,120 +32,223 @@ type Database interface {
 	DSN() string
 }
 
-// Init ...
-func Init() {
-	netConnection = make(map[string]net.Conn)
-	sshConnection = make(map[string]*ssh.Client)
-	sshConfig := map[string]SSHConfig{
-		//"PGSQL": {
-		//	Host: os.Getenv("PGSQL_SSH_HOST"),
-		//	User: os.Getenv("PGSQL_SSH_USER"),
-		//	Pass: os.Getenv("PGSQL_SSH_PASS"),
-		//	Port: os.Getenv("PGSQL_SSH_PORT"),
-		//},
-		//"PGSQL_REPLICA_1": {
-		//	Host: os.Getenv("PGSQL_SSH_REPLICA_1_HOST"),
-		//	User: os.Getenv("PGSQL_SSH_REPLICA_1_USER"),
-		//	Pass: os.Getenv("PGSQL_SSH_REPLICA_1_PASS"),
-		//	Port: os.Getenv("PGSQL_SSH_REPLICA_1_PORT"),
-		//},
-		"MYSQL": {
-			Host: os.Getenv("MYSQL_SSH_HOST"),
-			Port: os.Getenv("MYSQL_SSH_PORT"),
-			User: os.Getenv("MYSQL_SSH_USER"),
-			Pass: os.Getenv("MYSQL_SSH_PASS"),
-		},
-	}
-	for k, config := range sshConfig {
-		if netConnection[k], sshConnection[k], err = config.Open(); err != nil {
-			panic(fmt.Errorf("connection to ssh %s, error: %v", k, err))
+// drivers build the Database of a connection for its driver, the tunnel is nil
+// unless the connection goes through SSH.
+var drivers = map[string]func(c *config.ConnectionConfig, sshTunnel *tunnel.Tunnel) Database{
+	"postgres": func(c *config.ConnectionConfig, sshTunnel *tunnel.Tunnel) Database {
+		read := make([]psql.DBConfig, 0, len(c.Read))
+		for _, r := range c.Read {
+			read = append(read, psqlNode(r, sshTunnel))
 		}
-		logrus.Info(fmt.Sprintf("successfully connected to ssh %s", k))
-	}
+		return psql.Config{
+			Name:             c.Name,
+			Write:            psqlNode(c.Write, sshTunnel),
+			Read:             read,
+			MaxLag:           c.ReadMaxLag,
+			LagCheckInterval: c.ReadCheckInterval,
+			MaxOpenConns:     c.MaxOpenConns,
+			MaxIdleConns:     c.MaxIdleConns,
+			ConnMaxLifetime:  c.ConnMaxLifetime,
+			ConnMaxIdleTime:  c.ConnMaxIdleTime,
+			ConnectTimeout:   c.ConnectTimeout,
+			Logger:           queryLogger(c),
+		}
+	},
+	"mysql": func(c *config.ConnectionConfig, sshTunnel *tunnel.Tunnel) Database {
+		read := make([]msql.DBConfig, 0, len(c.Read))
+		for _, r := range c.Read {
+			read = append(read, msqlNode(r, sshTunnel))
+		}
+		return msql.Config{
+			Name:            c.Name,
+			Write:           msqlNode(c.Write, sshTunnel),
+			Read:            read,
+			MaxOpenConns:    c.MaxOpenConns,
+			MaxIdleConns:    c.MaxIdleConns,
+			ConnMaxLifetime: c.ConnMaxLifetime,
+			ConnMaxIdleTime: c.ConnMaxIdleTime,
+			ConnectTimeout:  c.ConnectTimeout,
+			Logger:          queryLogger(c),
+		}
+	},
+	// a file on the local disk, without replicas nor SSH tunnel
+	"sqlite": func(c *config.ConnectionConfig, _ *tunnel.Tunnel) Database {
+		return sqlite.Config{
+			Name:        c.Name,
+			Path:        c.Write.Name,
+			BusyTimeout: c.ConnectTimeout,
+			Logger:      queryLogger(c),
+		}
+	},
+}
+
+// Init connects the connections declared in config.Connections, waiting for
+// them as configured by config.Dependency. It is meant for commands, the
+// server adds Dependencies to its own dependency.Manager.
+func Init() error {
+	cfg := config.Dependency()
+	m := dependency.NewManager(cfg.StartTimeout, cfg.CheckInterval, cfg.MaxBackoff)
+	m.Add(Dependencies()...)
+	return m.Start(context.Background())
+}
 
+// Dependencies returns the SSH tunnels and the database connections declared
+// in config.Connections. A connection is opened on its first successful
+// Connect, its pool dials again on its own afterwards. The tunnel of a
+// connection is optional when the connection is.
+func Dependencies() []dependency.Dependency {
+	mu.Lock()
+	tunnels = make(map[string]*tunnel.Tunnel)
 	dbConnections = make(map[string]*gorm.DB)
-	dbConfig := map[string]Database{
-		"PGSQL_DB_BAF": psql.Config{
-			Write: psql.DBConfig{
-				Host:    config.DB().Host,
-				User:    config.DB().User,
-				Pass:    config.DB().Pass,
-				Port:    config.DB().Port,
-				Name:    config.DB().Name,
-				SSLMode: config.DB().SSLMode,
-				TZ:      config.DB().TZ,
-				// SSHClient: sshConnection["PGSQL"],
+	mu.Unlock()
+
+	var deps []dependency.Dependency
+	for _, c := range config.Connections() {
+		optional := config.Dependency().IsOptional("db:" + c.Name)
+
+		var sshTunnel *tunnel.Tunnel
+		if c.SSH != nil {
+			sshTunnel = tunnel.New(c.Name, *c.SSH)
+			mu.Lock()
+			tunnels[c.Name] = sshTunnel
+			mu.Unlock()
+			deps = append(deps, dependency.Dependency{
+				Name:     "ssh:" + c.Name,
+				Optional: optional,
+				Connect:  sshTunnel.Connect,
+				Check:    sshTunnel.Check,
+			})
+		}
+
+		deps = append(deps, dependency.Dependency{
+			Name:     "db:" + c.Name,
+			Optional: optional,
+			Connect: func(_ context.Context) error {
+				_, err := open(c, sshTunnel)
+				return err
 			},
-			// Read: []psql.DBConfig{
-			// {
-			// Host:    os.Getenv("PGSQL_DB_BAF_REPLICA_1_HOST"),
-			// User:    os.Getenv("PGSQL_DB_BAF_REPLICA_1_USER"),
-			// Pass:    os.Getenv("PGSQL_DB_BAF_REPLICA_1_PASS"),
-			// Port:    os.Getenv("PGSQL_DB_BAF_REPLICA_1_PORT"),
-			// Name:    os.Getenv("PGSQL_DB_BAF_REPLICA_1_NAME"),
-			// SslMode: os.Getenv("PGSQL_DB_BAF_REPLICA_1_SSLMODE"),
-			// Tz:      os.Getenv("PGSQL_DB_BAF_REPLICA_1_TZ"),
-			// SSHClient: sshConnection["PGSQL_REPLICA_1"],
-			// },
-			// },
-		},
-		"MYSQL": msql.Config{
-			Write: msql.DBConfig{
-				Host:      os.Getenv("MYSQL_DB_HOST"),
-				User:      os.Getenv("MYSQL_DB_USER"),
-				Pass:      os.Getenv("MYSQL_DB_PASS"),
-				Port:      os.Getenv("MYSQL_DB_PORT"),
-				Name:      os.Getenv("MYSQL_DB_NAME"),
-				ParseTime: os.Getenv("MYSQL_DB_PARSE_TIME"),
-				SSHClient: sshConnection["MYSQL"],
+			Check: func(ctx context.Context) error {
+				db, ok := Lookup(c.Name)
+				if !ok {
+					return fmt.Errorf("connection %s is not open", c.Name)
+				}
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
 			},
-		},
+		})
 	}
-	var sqlDB *sql.DB
-	for k, db := range dbConfig {
-		if dbConnections[k], err = db.Open(); err != nil {
-			panic(fmt.Errorf("connection to db %s, error: %v", k, err))
-		}
+	return deps
+}
 
-		if sqlDB, err = dbConnections[k].DB(); err != nil {
-			panic(fmt.Errorf("connection to db %s, error: %v", k, err))
-		}
+// open opens the connection c unless it is open.
+func open(c *config.ConnectionConfig, sshTunnel *tunnel.Tunnel) (*gorm.DB, error) {
+	if db, ok := Lookup(c.Name); ok {
+		return db, nil
+	}
 
-		sqlDB.SetMaxOpenConns(20)
-		sqlDB.SetMaxIdleConns(5)
-		sqlDB.SetConnMaxLifetime(5 * time.Minute)
-		sqlDB.SetConnMaxIdleTime(time.Hour)
+	driver, ok := drivers[c.Driver]
+	if !ok {
+		return nil, fmt.Errorf("connection to db %s, error: unknown driver %q", c.Name, c.Driver)
+	}
+	db, err := driver(c, sshTunnel).Open()
+	if err != nil {
+		return nil, fmt.Errorf("connection to db %s, error: %v", c.Name, err)
+	}
 
-		if err = sqlDB.Ping(); err != nil {
-			panic(fmt.Errorf("connection to db %s, error: %v", k, err))
-		}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("connection to db %s, error: %v", c.Name, err)
+	}
+	sqlDB.SetMaxOpenConns(c.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(c.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+
+	mu.Lock()
+	dbConnections[c.Name] = db
+	mu.Unlock()
+	logrus.Info(fmt.Sprintf("successfully connected to db %s", c.Name))
+	return db, nil
+}
 
-		logrus.Info(fmt.Sprintf("successfully connected to db %s", k))
+func psqlNode(node config.DBConfig, sshTunnel *tunnel.Tunnel) psql.DBConfig {
+	n := psql.DBConfig{
+		Host:    node.Host,
+		User:    node.User,
+		Pass:    node.Pass,
+		Port:    node.Port,
+		Name:    node.Name,
+		SSLMode: node.SSLMode,
+		TZ:      node.TZ,
 	}
+	if sshTunnel != nil {
+		n.Tunnel = sshTunnel
+	}
+	return n
 }
 
-// Connection ...
+func msqlNode(node config.DBConfig, sshTunnel *tunnel.Tunnel) msql.DBConfig {
+	n := msql.DBConfig{
+		Host:      node.Host,
+		User:      node.User,
+		Pass:      node.Pass,
+		Port:      node.Port,
+		Name:      node.Name,
+		ParseTime: node.ParseTime,
+	}
+	if sshTunnel != nil {
+		n.Tunnel = sshTunnel
+	}
+	return n
+}
+
+func queryLogger(c *config.ConnectionConfig) *querylog.Logger {
+	return querylog.New(c.Name, querylog.Config{
+		Level:         c.LogLevel,
+		SlowThreshold: c.SlowThreshold,
+		Params:        c.LogParams,
+	})
+}
+
+// Connection returns the open connection named name, it panics when the
+// connection isn't open.
 func Connection(name string) *gorm.DB {
-	if dbConnections[name] == nil {
-		panic("connection is undefined")
+	db, ok := Lookup(name)
+	if !ok {
+		panic(fmt.Sprintf("connection %s is undefined", name))
 	}
-	return dbConnections[name]
+	return db
 }
 
-// PSQL ...
-func PSQL() *gorm.DB {
-	return Connection("PGSQL_DB_BAF")
+// Lookup returns the open connection named name, ok is false when the
+// connection isn't declared or couldn't be opened yet.
+func Lookup(name string) (db *gorm.DB, ok bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	db, ok = dbConnections[name]
+	return
 }
 
-// MYSQL ...
-func MYSQL() *gorm.DB {
-	return Connection("MYSQL")
+// PSQL returns the default connection.
+func PSQL() *gorm.DB {
+	return Connection(config.DefaultConnection)
 }
 
 // Close ...
 func Close() {
+	mu.Lock()
+	defer mu.Unlock()
+
 	var sqlDB *sql.DB
 	for k, db := range dbConnections {
+		if set := replica.FromDB(db); set != nil {
+			if err = set.Close(); err != nil {
+				logrus.WithField("message", "failed to close replica connections "+k).Error(err.Error())
+			}
+		}
 		if sqlDB, err = db.DB(); err == nil {
 			err = sqlDB.Close()
 		}
@@ -153,23 +259,11 @@ func Close() {
 		}
 	}
 
-	for key, conn := range sshConnection {
-		if conn != nil {
-			if err = conn.Close(); err != nil {
-				logrus.WithField("message", "failed to close ssh connection "+key).Error(err.Error())
-			} else {
-				logrus.Infof("ssh connection to %v closed", key)
-			}
-		}
-	}
-
-	for key, conn := range netConnection {
-		if conn != nil {
-			if err = conn.Close(); err != nil {
-				logrus.WithField("message", "failed to close net connection "+key).Error(err.Error())
-			} else {
-				logrus.Infof("net connection to %v closed", key)
-			}
+	for key, sshTunnel := range tunnels {
+		if err = sshTunnel.Close(); err != nil {
+			logrus.WithField("message", "failed to close ssh connection "+key).Error(err.Error())
+		} else {
+			logrus.Infof("ssh connection to %v closed", key)
 		}
 	}
 }
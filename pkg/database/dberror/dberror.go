@@ -0,0 +1,261 @@
+// Package dberror classifies the errors of the PostgreSQL, MySQL and SQLite
+// drivers, so that services can rely on the constraints of the database
+// instead of checking them beforehand.
+package dberror
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// Kind is the class of a database error.
+type Kind string
+
+const (
+	// Duplicate violates a unique constraint.
+	Duplicate Kind = "duplicate"
+	// Missing references a row that doesn't exist.
+	Missing Kind = "missing"
+	// Referenced deletes or updates a row still referenced by another one.
+	Referenced Kind = "referenced"
+	// Invalid violates a check or not null constraint.
+	Invalid Kind = "invalid"
+	// Conflict is a serialization failure or a deadlock, the transaction
+	// may succeed when retried.
+	Conflict Kind = "conflict"
+	// Timeout is a statement cancelled by a timeout or waiting too long for
+	// a lock.
+	Timeout Kind = "timeout"
+)
+
+// Error is a classified database error.
+type Error struct {
+	Kind Kind
+	// Constraint is the name of the violated constraint or index, when the
+	// driver reports it.
+	Constraint string
+	// Columns are the columns of the violated constraint, when the driver
+	// reports them.
+	Columns []string
+	// Retryable is set when the same statement may succeed later.
+	Retryable bool
+
+	err error
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap ...
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Field returns the column the error is about, or the constraint when the
+// columns are unknown.
+func (e *Error) Field() string {
+	if len(e.Columns) > 0 {
+		return e.Columns[len(e.Columns)-1]
+	}
+	return e.Constraint
+}
+
+var (
+	// Key (username)=(budi) already exists.
+	pgKey = regexp.MustCompile(`^Key \((.+?)\)=`)
+	// Duplicate entry 'budi' for key 'm_user.uq_m_user_username'
+	mysqlKey = regexp.MustCompile(`for key '([^']+)'`)
+	// CONSTRAINT `fk_m_user_role_id` FOREIGN KEY (`role_id`)
+	mysqlForeignKey = regexp.MustCompile("CONSTRAINT `([^`]+)` FOREIGN KEY \\(`([^`]+)`\\)")
+	// Check constraint 'chk_name' is violated. / Column 'name' cannot be null
+	mysqlQuoted = regexp.MustCompile(`'([^']+)'`)
+	// constraint failed: UNIQUE constraint failed: m_user.username (2067)
+	sqliteColumns = regexp.MustCompile(`(?:UNIQUE|PRIMARY KEY|NOT NULL|CHECK) constraint failed: (.+?)(?: \(\d+\))?$`)
+)
+
+// Parse classifies err, it returns nil when err isn't one of the database
+// errors of Kind.
+func Parse(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var (
+		pgErr     *pgconn.PgError
+		pqErr     *pq.Error
+		stateErr  interface{ SQLState() string }
+		mysqlErr  *mysql.MySQLError
+		sqliteErr interface{ Code() int }
+	)
+	switch {
+	case errors.As(err, &pgErr):
+		return postgresError(err, pgErr.Code, pgErr.ConstraintName, pgErr.ColumnName, pgErr.Detail)
+	case errors.As(err, &pqErr):
+		return postgresError(err, string(pqErr.Code), pqErr.Constraint, pqErr.Column, pqErr.Detail)
+	case errors.As(err, &stateErr):
+		// another driver reporting the SQLSTATE only
+		return postgresError(err, stateErr.SQLState(), "", "", "")
+	case errors.As(err, &mysqlErr):
+		return mysqlError(err, mysqlErr)
+	case errors.As(err, &sqliteErr):
+		return sqliteError(err, sqliteErr.Code())
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: Timeout, Retryable: true, err: err}
+	}
+	return nil
+}
+
+// Is reports whether err is a database error of kind.
+func Is(err error, kind Kind) bool {
+	e := Parse(err)
+	return e != nil && e.Kind == kind
+}
+
+// Retryable reports whether err is a database error that may not happen
+// again when retried.
+func Retryable(err error) bool {
+	e := Parse(err)
+	return e != nil && e.Retryable
+}
+
+func postgresError(err error, code, constraint, column, detail string) *Error {
+	e := &Error{Constraint: constraint, err: err}
+	if column != "" {
+		e.Columns = []string{column}
+	} else if m := pgKey.FindStringSubmatch(detail); m != nil {
+		e.Columns = splitColumns(m[1])
+	}
+
+	switch code {
+	case "23505":
+		e.Kind = Duplicate
+	case "23503":
+		e.Kind = Missing
+		if strings.Contains(detail, "is still referenced") {
+			e.Kind = Referenced
+		}
+	case "23502", "23514":
+		e.Kind = Invalid
+	case "40001", "40P01":
+		e.Kind, e.Retryable = Conflict, true
+	case "57014", "55P03":
+		e.Kind, e.Retryable = Timeout, true
+	default:
+		return nil
+	}
+	return e
+}
+
+func mysqlError(err error, mysqlErr *mysql.MySQLError) *Error {
+	e := &Error{err: err}
+	switch mysqlErr.Number {
+	case 1062:
+		e.Kind = Duplicate
+		if m := mysqlKey.FindStringSubmatch(mysqlErr.Message); m != nil {
+			// MySQL 8 prefixes the index with its table
+			e.Constraint = m[1][strings.LastIndex(m[1], ".")+1:]
+		}
+	case 1451, 1452:
+		e.Kind = Missing
+		if mysqlErr.Number == 1451 {
+			e.Kind = Referenced
+		}
+		if m := mysqlForeignKey.FindStringSubmatch(mysqlErr.Message); m != nil {
+			e.Constraint, e.Columns = m[1], []string{m[2]}
+		}
+	case 3819:
+		e.Kind = Invalid
+		if m := mysqlQuoted.FindStringSubmatch(mysqlErr.Message); m != nil {
+			e.Constraint = m[1]
+		}
+	case 1048:
+		e.Kind = Invalid
+		if m := mysqlQuoted.FindStringSubmatch(mysqlErr.Message); m != nil {
+			e.Columns = []string{m[1]}
+		}
+	case 1213:
+		e.Kind, e.Retryable = Conflict, true
+	case 1205, 3024, 1317:
+		e.Kind, e.Retryable = Timeout, true
+	default:
+		return nil
+	}
+	return e
+}
+
+// the extended result codes of SQLite
+const (
+	sqliteBusy              = 5
+	sqliteLocked            = 6
+	sqliteConstraintCheck   = 275
+	sqliteConstraintFK      = 787
+	sqliteConstraintNotNull = 1299
+	sqliteConstraintPK      = 1555
+	sqliteConstraintUnique  = 2067
+	sqliteInterrupt         = 9
+	sqlitePrimaryMask       = 0xff
+)
+
+func sqliteError(err error, code int) *Error {
+	e := &Error{err: err}
+	if m := sqliteColumns.FindStringSubmatch(err.Error()); m != nil {
+		for _, column := range splitColumns(m[1]) {
+			// table.column
+			e.Columns = append(e.Columns, column[strings.LastIndex(column, ".")+1:])
+		}
+	}
+
+	switch code {
+	case sqliteConstraintUnique, sqliteConstraintPK:
+		e.Kind = Duplicate
+	case sqliteConstraintFK:
+		// SQLite doesn't tell which side of the key failed
+		e.Kind = Missing
+	case sqliteConstraintCheck, sqliteConstraintNotNull:
+		e.Kind = Invalid
+		if code == sqliteConstraintCheck && len(e.Columns) == 1 {
+			e.Constraint, e.Columns = e.Columns[0], nil
+		}
+	default:
+		switch code & sqlitePrimaryMask {
+		case sqliteBusy, sqliteLocked:
+			e.Kind, e.Retryable = Conflict, true
+		case sqliteInterrupt:
+			e.Kind, e.Retryable = Timeout, true
+		default:
+			return nil
+		}
+	}
+	return e
+}
+
+// splitColumns splits a list of columns, unwrapping the expressions of
+// functional indexes, e.g. "tenant_id, lower(name::text)".
+func splitColumns(list string) []string {
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		column = strings.TrimSpace(column)
+		if i := strings.LastIndex(column, "("); i >= 0 {
+			column = column[i+1:]
+		}
+		column = strings.TrimRight(column, ")")
+		if i := strings.Index(column, "::"); i >= 0 {
+			column = column[:i]
+		}
+		if column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,104 @@
+package dberror
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want *Error
+	}{
+		{
+			"pgx duplicate",
+			&pgconn.PgError{Code: "23505", ConstraintName: "uq_m_role_name", Detail: "Key (tenant_id, lower(name::text))=(default, admin) already exists."},
+			&Error{Kind: Duplicate, Constraint: "uq_m_role_name", Columns: []string{"tenant_id", "name"}},
+		},
+		{
+			"pgx referenced",
+			&pgconn.PgError{Code: "23503", ConstraintName: "fk_m_user_role_id", Detail: `Key (id)=(1) is still referenced from table "m_user".`},
+			&Error{Kind: Referenced, Constraint: "fk_m_user_role_id", Columns: []string{"id"}},
+		},
+		{
+			"pq missing",
+			fmt.Errorf("create: %w", &pq.Error{Code: "23503", Constraint: "fk_m_user_role_id", Detail: `Key (role_id)=(9) is not present in table "m_role".`}),
+			&Error{Kind: Missing, Constraint: "fk_m_user_role_id", Columns: []string{"role_id"}},
+		},
+		{
+			"pgx not null",
+			&pgconn.PgError{Code: "23502", ColumnName: "name"},
+			&Error{Kind: Invalid, Columns: []string{"name"}},
+		},
+		{"pgx serialization", &pgconn.PgError{Code: "40001"}, &Error{Kind: Conflict, Retryable: true}},
+		{"pgx canceled", &pgconn.PgError{Code: "57014"}, &Error{Kind: Timeout, Retryable: true}},
+		{"pgx other", &pgconn.PgError{Code: "42P01"}, nil},
+		{
+			"mysql duplicate",
+			&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'budi' for key 'm_user.uq_m_user_username'"},
+			&Error{Kind: Duplicate, Constraint: "uq_m_user_username"},
+		},
+		{
+			"mysql referenced",
+			&mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row: a foreign key constraint fails (`db`.`m_user`, CONSTRAINT `fk_m_user_role_id` FOREIGN KEY (`role_id`) REFERENCES `m_role` (`id`))"},
+			&Error{Kind: Referenced, Constraint: "fk_m_user_role_id", Columns: []string{"role_id"}},
+		},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, &Error{Kind: Conflict, Retryable: true}},
+		{"other", fmt.Errorf("boom"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.err)
+			if got != nil {
+				got.err = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_sqlite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE m_role (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE m_user (id INTEGER PRIMARY KEY, username TEXT NOT NULL UNIQUE, role_id INTEGER REFERENCES m_role (id))",
+		"INSERT INTO m_role (id) VALUES (1)",
+		"INSERT INTO m_user (username, role_id) VALUES ('budi', 1)",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		stmt string
+		want *Error
+	}{
+		{"INSERT INTO m_user (username, role_id) VALUES ('budi', 1)", &Error{Kind: Duplicate, Columns: []string{"username"}}},
+		{"INSERT INTO m_user (username, role_id) VALUES ('sari', 9)", &Error{Kind: Missing}},
+		{"INSERT INTO m_user (username) VALUES (NULL)", &Error{Kind: Invalid, Columns: []string{"username"}}},
+	}
+	for _, tt := range tests {
+		got := Parse(db.Exec(tt.stmt).Error)
+		if got != nil {
+			got.err = nil
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%s) = %#v, want %#v", tt.stmt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Package dialect builds the conditions whose SQL differs between the
+// drivers of pkg/database. They are clause expressions written for the
+// dialect of the statement they are added to, e.g.
+//
+//	db.Where(dialect.ILike{Column: clause.Column{Name: "name"}, Value: "%budi%"})
+package dialect
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// The names of the gorm dialectors.
+const (
+	Postgres = "postgres"
+	MySQL    = "mysql"
+	SQLite   = "sqlite"
+)
+
+// Name returns the dialect of db.
+func Name(db *gorm.DB) string {
+	return db.Dialector.Name()
+}
+
+func name(builder clause.Builder) string {
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		return stmt.Dialector.Name()
+	}
+	return ""
+}
+
+// likeEscaper escapes the LIKE metacharacters of user input, for ESCAPE '!'
+// which, unlike the backslash, means the same in every dialect.
+var likeEscaper = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+// similarEscaper escapes the SIMILAR TO metacharacters of user input.
+var similarEscaper = strings.NewReplacer(
+	`\`, `\\`, `%`, `\%`, `_`, `\_`, `|`, `\|`, `*`, `\*`, `+`, `\+`, `?`, `\?`,
+	`{`, `\{`, `}`, `\}`, `(`, `\(`, `)`, `\)`, `[`, `\[`, `]`, `\]`,
+)
+
+// Like matches Column against the LIKE pattern Value, whose metacharacters
+// are escaped with a backslash as in PostgreSQL and MySQL.
+type Like struct {
+	Column interface{}
+	Value  interface{}
+}
+
+// Build ...
+func (l Like) Build(builder clause.Builder) {
+	builder.AddVar(builder, l.Column)
+	builder.WriteString(" LIKE ")
+	builder.AddVar(builder, l.Value)
+	backslashEscape(builder)
+}
+
+// ILike is Like ignoring case.
+type ILike struct {
+	Column interface{}
+	Value  interface{}
+}
+
+// Build ...
+func (l ILike) Build(builder clause.Builder) {
+	if name(builder) == Postgres {
+		builder.AddVar(builder, l.Column)
+		builder.WriteString(" ILIKE ")
+		builder.AddVar(builder, l.Value)
+		return
+	}
+	builder.WriteString("LOWER(")
+	builder.AddVar(builder, l.Column)
+	builder.WriteString(") LIKE LOWER(")
+	builder.AddVar(builder, l.Value)
+	builder.WriteString(")")
+	backslashEscape(builder)
+}
+
+// backslashEscape declares the backslash as the escape of LIKE on SQLite,
+// which has none by default.
+func backslashEscape(builder clause.Builder) {
+	if name(builder) == SQLite {
+		builder.WriteString(` ESCAPE '\'`)
+	}
+}
+
+// ContainsAny matches Column containing any of Values ignoring case, which
+// are taken literally. It is SIMILAR TO on PostgreSQL and LIKE elsewhere.
+// Empty values are ignored, without any other the condition matches every
+// row.
+type ContainsAny struct {
+	Column interface{}
+	Values []string
+}
+
+// Build ...
+func (c ContainsAny) Build(builder clause.Builder) {
+	values := make([]string, 0, len(c.Values))
+	for _, v := range c.Values {
+		if v != "" {
+			values = append(values, strings.ToLower(v))
+		}
+	}
+	if len(values) == 0 {
+		builder.WriteString("1 = 1")
+		return
+	}
+
+	if name(builder) == Postgres {
+		for i, v := range values {
+			values[i] = similarEscaper.Replace(v)
+		}
+		builder.WriteString("LOWER(")
+		builder.AddVar(builder, c.Column)
+		builder.WriteString(") SIMILAR TO ")
+		builder.AddVar(builder, "%("+strings.Join(values, "|")+")%")
+		return
+	}
+
+	builder.WriteByte('(')
+	for i, v := range values {
+		if i > 0 {
+			builder.WriteString(" OR ")
+		}
+		builder.WriteString("LOWER(")
+		builder.AddVar(builder, c.Column)
+		builder.WriteString(") LIKE ")
+		builder.AddVar(builder, "%"+likeEscaper.Replace(v)+"%")
+		builder.WriteString(" ESCAPE '!'")
+	}
+	builder.WriteByte(')')
+}
+
+// WebSearch matches the full-text Column against Query, in web search
+// syntax, e.g. "budi -admin". On PostgreSQL Column is a tsvector. Elsewhere
+// it is lowercase text containing every word of Query and none of the words
+// prefixed with a minus, quoted phrases are words.
+type WebSearch struct {
+	Column interface{}
+	Query  string
+}
+
+// Build ...
+func (s WebSearch) Build(builder clause.Builder) {
+	if name(builder) == Postgres {
+		builder.AddVar(builder, s.Column)
+		builder.WriteString(" @@ websearch_to_tsquery('simple', ")
+		builder.AddVar(builder, s.Query)
+		builder.WriteString(")")
+		return
+	}
+
+	terms := searchTerms(s.Query)
+	if len(terms) == 0 {
+		builder.WriteString("1 = 1")
+		return
+	}
+	builder.WriteByte('(')
+	for i, t := range terms {
+		if i > 0 {
+			builder.WriteString(" AND ")
+		}
+		builder.AddVar(builder, s.Column)
+		if t.exclude {
+			builder.WriteString(" NOT")
+		}
+		builder.WriteString(" LIKE ")
+		builder.AddVar(builder, "%"+likeEscaper.Replace(strings.ToLower(t.text))+"%")
+		builder.WriteString(" ESCAPE '!'")
+	}
+	builder.WriteByte(')')
+}
+
+type searchTerm struct {
+	text    string
+	exclude bool
+}
+
+// searchTerms splits a web search query into its words and quoted phrases,
+// the OR operator isn't supported and matches as a word.
+func searchTerms(query string) []searchTerm {
+	var terms []searchTerm
+	for query = strings.TrimSpace(query); query != ""; query = strings.TrimSpace(query) {
+		var t searchTerm
+		if query[0] == '-' {
+			t.exclude, query = true, query[1:]
+		}
+		if strings.HasPrefix(query, `"`) {
+			end := strings.Index(query[1:], `"`)
+			if end < 0 {
+				end = len(query) - 1
+			}
+			t.text, query = query[1:end+1], query[min(end+2, len(query)):]
+		} else {
+			end := strings.IndexAny(query, " \t\n")
+			if end < 0 {
+				end = len(query)
+			}
+			t.text, query = query[:end], query[end:]
+		}
+		if t.text = strings.TrimSpace(t.text); t.text != "" {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
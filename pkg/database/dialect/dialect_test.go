@@ -0,0 +1,86 @@
+package dialect
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func dryRun(t *testing.T, dialector gorm.Dialector) *gorm.DB {
+	db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestBuild(t *testing.T) {
+	pg := dryRun(t, postgres.New(postgres.Config{DSN: "host=localhost"}))
+	lite := dryRun(t, sqlite.Open(":memory:"))
+	name := clause.Column{Name: "name"}
+
+	tests := []struct {
+		name string
+		db   *gorm.DB
+		expr clause.Expression
+		want string
+	}{
+		{"ilike postgres", pg, ILike{Column: name, Value: "%a%"}, `SELECT * FROM "m_user" WHERE "name" ILIKE $1`},
+		{"ilike sqlite", lite, ILike{Column: name, Value: "%a%"}, "SELECT * FROM `m_user` WHERE LOWER(`name`) LIKE LOWER(?) ESCAPE '\\'"},
+		{"contains postgres", pg, ContainsAny{Column: name, Values: []string{"a", "b"}}, `SELECT * FROM "m_user" WHERE LOWER("name") SIMILAR TO $1`},
+		{"contains sqlite", lite, ContainsAny{Column: name, Values: []string{"a", "b"}}, "SELECT * FROM `m_user` WHERE (LOWER(`name`) LIKE ? ESCAPE '!' OR LOWER(`name`) LIKE ? ESCAPE '!')"},
+		{"search postgres", pg, WebSearch{Column: name, Query: "budi -admin"}, `SELECT * FROM "m_user" WHERE "name" @@ websearch_to_tsquery('simple', $1)`},
+		{"search sqlite", lite, WebSearch{Column: name, Query: `"budi s" -admin`}, "SELECT * FROM `m_user` WHERE (`name` LIKE ? ESCAPE '!' AND `name` NOT LIKE ? ESCAPE '!')"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := tt.db.Table("m_user").Where(tt.expr).Find(&[]map[string]interface{}{}).Statement
+			if got := stmt.SQL.String(); got != tt.want {
+				t.Errorf("SQL = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsAny_empty(t *testing.T) {
+	pg := dryRun(t, postgres.New(postgres.Config{DSN: "host=localhost"}))
+	lite := dryRun(t, sqlite.Open(":memory:"))
+	name := clause.Column{Name: "name"}
+
+	tests := []struct {
+		name   string
+		db     *gorm.DB
+		values []string
+		want   string
+	}{
+		{"postgres", pg, []string{"A", ""}, `SELECT * FROM "m_user" WHERE LOWER("name") SIMILAR TO $1 [%(a)%]`},
+		{"sqlite", lite, []string{"", "a"}, "SELECT * FROM `m_user` WHERE (LOWER(`name`) LIKE ? ESCAPE '!') [%a%]"},
+		{"nothing postgres", pg, []string{""}, `SELECT * FROM "m_user" WHERE 1 = 1 []`},
+		{"nothing sqlite", lite, nil, "SELECT * FROM `m_user` WHERE 1 = 1 []"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := tt.db.Table("m_user").Where(ContainsAny{Column: name, Values: tt.values}).Find(&[]map[string]interface{}{}).Statement
+			if got := fmt.Sprint(stmt.SQL.String(), " ", stmt.Vars); got != tt.want {
+				t.Errorf("ContainsAny%q = %s, want %s", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms(`budi  -admin "jl. merdeka" -"x y`)
+	want := []searchTerm{{"budi", false}, {"admin", true}, {"jl. merdeka", false}, {"x y", true}}
+	if len(got) != len(want) {
+		t.Fatalf("searchTerms() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("searchTerms()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package migrations holds the versioned SQL migrations of each database
+// connection and applies them.
+//
+// The migrations of a connection live in the directory named after the
+// connection in lowercase, e.g. pgsql_db_baf for PGSQL_DB_BAF, as a pair of
+// files per version:
+//
+//	<version>_<name>.up.sql
+//	<version>_<name>.down.sql
+//
+// Versions are UTC timestamps (20060102150405) and are applied in order. A
+// version whose SQL differs on a driver adds files for that driver, used
+// instead of the common ones on it:
+//
+//	<version>_<name>.sqlite.up.sql
+//	<version>_<name>.sqlite.down.sql
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed */*.sql
+var files embed.FS
+
+// VersionLayout formats the version of new migrations.
+const VersionLayout = "20060102150405"
+
+var (
+	filePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)(?:\.(postgres|mysql|sqlite))?\.(up|down)\.sql$`)
+	namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migration is a version of the schema of a connection.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of Up, a migration must not change once applied.
+	Checksum string
+}
+
+// Dir returns the directory holding the migrations of connection.
+func Dir(connection string) string {
+	return strings.ToLower(connection)
+}
+
+// Connections returns the names of the connections that have migrations.
+func Connections() []string {
+	entries, _ := files.ReadDir(".")
+	connections := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			connections = append(connections, strings.ToUpper(entry.Name()))
+		}
+	}
+	return connections
+}
+
+// Load returns the migrations of connection for dialect, the name of the
+// gorm dialector, ordered by version.
+func Load(connection, dialect string) ([]*Migration, error) {
+	entries, err := files.ReadDir(Dir(connection))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	// the files of dialect, which replace the common ones
+	specific := make(map[string]bool)
+	for _, entry := range entries {
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			return nil, fmt.Errorf("migrations: unexpected file %s/%s", Dir(connection), entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		key := match[1] + "." + match[4]
+		if match[3] != "" && match[3] != dialect || match[3] == "" && specific[key] {
+			continue
+		}
+		if match[3] != "" {
+			specific[key] = true
+		}
+		content, err := files.ReadFile(path.Join(Dir(connection), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrations: version %d of %s is used by %s and %s", version, connection, m.Name, match[2])
+		}
+		if match[4] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrations: %d_%s of %s has no up file", m.Version, m.Name, connection)
+		}
+		sum := sha256.Sum256([]byte(m.Up))
+		m.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Create writes empty up and down files for a new migration of connection in
+// dir, the source directory of this package, and returns their paths. The
+// migration is embedded on the next build.
+func Create(dir, connection, name string, now time.Time) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("migrations: name %q must be snake_case", name)
+	}
+	dir = filepath.Join(dir, Dir(connection))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	version := now.UTC().Format(VersionLayout)
+	paths := []string{
+		filepath.Join(dir, version+"_"+name+".up.sql"),
+		filepath.Join(dir, version+"_"+name+".down.sql"),
+	}
+	for _, p := range paths {
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		if err = f.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// split breaks a script into its statements on the semicolons outside of
+// quotes, comments and dollar quoted bodies.
+func split(script string) []string {
+	var (
+		statements []string
+		start      int
+	)
+	add := func(stmt string) {
+		if strings.TrimSpace(stripComments(stmt)) != "" {
+			statements = append(statements, strings.TrimSpace(stmt))
+		}
+	}
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = closingQuote(script, i)
+		case strings.HasPrefix(script[i:], "--"):
+			i = indexFrom(script, i, "\n")
+		case strings.HasPrefix(script[i:], "/*"):
+			i = indexFrom(script, i+2, "*/") + 1
+		case c == '$':
+			if tag := dollarTag(script[i:]); tag != "" {
+				i = indexFrom(script, i+len(tag), tag) + len(tag) - 1
+			}
+		case c == ';':
+			add(script[start:i])
+			start = i + 1
+		}
+	}
+	add(script[start:])
+	return statements
+}
+
+// closingQuote returns the index of the quote closing the one at i, doubled
+// quotes are escapes.
+func closingQuote(script string, i int) int {
+	q := script[i]
+	for j := i + 1; j < len(script); j++ {
+		if script[j] != q {
+			continue
+		}
+		if j+1 < len(script) && script[j+1] == q {
+			j++
+			continue
+		}
+		return j
+	}
+	return len(script)
+}
+
+// indexFrom returns the index of substr in script after i, or the end of
+// script.
+func indexFrom(script string, i int, substr string) int {
+	if i >= len(script) {
+		return len(script)
+	}
+	if n := strings.Index(script[i:], substr); n >= 0 {
+		return i + n
+	}
+	return len(script)
+}
+
+// dollarTag returns the $tag$ opening s, if any.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9':
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func stripComments(stmt string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(stmt, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"statements", "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n", []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"}},
+		{"no trailing semicolon", "SELECT 1", []string{"SELECT 1"}},
+		{"quoted", "INSERT INTO a VALUES ('x;y', 'it''s;');", []string{"INSERT INTO a VALUES ('x;y', 'it''s;')"}},
+		{"identifier", `SELECT 1 AS "a;b";`, []string{`SELECT 1 AS "a;b"`}},
+		{"line comment", "-- drop it; later\nSELECT 1;\n-- trailing comment\n", []string{"-- drop it; later\nSELECT 1"}},
+		{"block comment", "SELECT /* ; */ 1;", []string{"SELECT /* ; */ 1"}},
+		{
+			"dollar quoted",
+			"CREATE FUNCTION f() RETURNS INT AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql;\nSELECT f();",
+			[]string{"CREATE FUNCTION f() RETURNS INT AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql", "SELECT f()"},
+		},
+		{"tagged dollar quote", "DO $body$ BEGIN PERFORM 1; END $body$;", []string{"DO $body$ BEGIN PERFORM 1; END $body$"}},
+		{"placeholder", "SELECT $1;SELECT 2", []string{"SELECT $1", "SELECT 2"}},
+		{"empty", " ;\n-- nothing\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := split(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, connection := range Connections() {
+		for _, dialect := range []string{"postgres", "mysql", "sqlite"} {
+			migrations, err := Load(connection, dialect)
+			if err != nil {
+				t.Fatalf("Load(%s, %s) error = %v", connection, dialect, err)
+			}
+			for i, m := range migrations {
+				if m.Down == "" {
+					t.Errorf("%d_%s of %s has no %s down file", m.Version, m.Name, connection, dialect)
+				}
+				if i > 0 && m.Version <= migrations[i-1].Version {
+					t.Errorf("%d_%s of %s is out of order", m.Version, m.Name, connection)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	table = "schema_migrations"
+	// lockTimeout bounds the wait for another instance migrating the same
+	// database, on MySQL. PostgreSQL waits for the context.
+	lockTimeout = 10 * time.Minute
+)
+
+// Status is the state of a migration in the database.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Modified is set when the up file changed after it was applied and
+	// Missing when an applied migration has no file anymore.
+	Modified bool
+	Missing  bool
+}
+
+type applied struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator applies the migrations of a connection. Every run holds a database
+// level advisory lock, so instances starting together migrate one at a time,
+// and records the applied versions with the checksum of their up file in the
+// schema_migrations table. Each migration runs in its own transaction.
+type Migrator struct {
+	connection string
+	dialect    string
+	db         *sql.DB
+	migrations []*Migration
+	log        *logrus.Entry
+}
+
+// New returns the migrator of the connection named connection opened as db.
+func New(connection string, db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(connection, db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	// the migrator works on a single connection of the primary, below the
+	// prepared statements and the read replicas
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		connection: connection,
+		dialect:    db.Dialector.Name(),
+		db:         sqlDB,
+		migrations: migrations,
+		log:        logrus.WithField("db", connection),
+	}, nil
+}
+
+// Up applies steps pending migrations, all of them when steps is not
+// positive, and returns the applied ones. It refuses to run when an applied
+// migration changed.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]*Migration, error) {
+	var done []*Migration
+	err := m.run(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if a, ok := applied[mig.Version]; ok && a.checksum != mig.Checksum {
+				return fmt.Errorf("migrations: %d_%s of %s changed after it was applied", mig.Version, mig.Name, m.connection)
+			}
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			record := func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx,
+					"INSERT INTO "+table+" (version, name, checksum, applied_at) VALUES ("+m.bind(1)+", "+m.bind(2)+", "+m.bind(3)+", "+m.bind(4)+")",
+					mig.Version, mig.Name, mig.Checksum, time.Now().UTC())
+				return err
+			}
+			if err = m.exec(ctx, conn, mig, mig.Up, record); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts steps applied migrations from the latest, all of them when
+// steps is not positive, and returns the reverted ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	var done []*Migration
+	err := m.run(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for _, version := range versions {
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			mig := m.find(version)
+			if mig == nil {
+				return fmt.Errorf("migrations: %d_%s of %s has no file to revert", version, applied[version].name, m.connection)
+			}
+			record := func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE version = "+m.bind(1), mig.Version)
+				return err
+			}
+			if err = m.exec(ctx, conn, mig, mig.Down, record); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status returns the state of the migrations of the connection and of the
+// applied versions that have no file, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = m.createTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if a, ok := applied[mig.Version]; ok {
+			s.AppliedAt = &a.appliedAt
+			s.Modified = a.checksum != mig.Checksum
+			delete(applied, mig.Version)
+		}
+		status = append(status, s)
+	}
+	for version, a := range applied {
+		status = append(status, Status{Version: version, Name: a.name, AppliedAt: &a.appliedAt, Missing: true})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+// run calls fn on a connection holding the migration lock.
+func (m *Migrator) run(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err = m.lock(ctx, conn); err != nil {
+		return fmt.Errorf("migrations: failed to lock %s: %w", m.connection, err)
+	}
+	defer func() {
+		if err := m.unlock(context.WithoutCancel(ctx), conn); err != nil {
+			m.log.WithError(err).Warn("failed to release the migration lock")
+		}
+	}()
+
+	if err = m.createTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// exec runs script and record in a transaction.
+func (m *Migrator) exec(ctx context.Context, conn *sql.Conn, mig *Migration, script string, record func(tx *sql.Tx) error) (err error) {
+	start := time.Now()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			err = fmt.Errorf("migrations: %d_%s of %s: %w", mig.Version, mig.Name, m.connection, err)
+		}
+	}()
+
+	for _, stmt := range split(script) {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if err = record(tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	m.log.WithFields(logrus.Fields{
+		"version":  mig.Version,
+		"name":     mig.Name,
+		"duration": time.Since(start).String(),
+	}).Info("migration done")
+	return nil
+}
+
+func (m *Migrator) createTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+` (
+    version    BIGINT       NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    checksum   VARCHAR(64)  NOT NULL,
+    applied_at TIMESTAMP    NOT NULL
+)`)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]applied, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM "+table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]applied)
+	for rows.Next() {
+		var (
+			version int64
+			a       applied
+		)
+		if err = rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = a
+	}
+	return result, rows.Err()
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig
+		}
+	}
+	return nil
+}
+
+// lock takes the advisory lock of the connection, SQLite locks the whole
+// database during a transaction and needs none.
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	switch m.dialect {
+	case "postgres":
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", m.lockKey())
+		return err
+	case "mysql":
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", m.lockName(), int(lockTimeout.Seconds())).Scan(&acquired); err != nil {
+			return err
+		}
+		if acquired.Int64 != 1 {
+			return errors.New("timed out waiting for another migration")
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) unlock(ctx context.Context, conn *sql.Conn) error {
+	var err error
+	switch m.dialect {
+	case "postgres":
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", m.lockKey())
+	case "mysql":
+		_, err = conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", m.lockName())
+	}
+	return err
+}
+
+func (m *Migrator) lockName() string {
+	return table + ":" + Dir(m.connection)
+}
+
+func (m *Migrator) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(m.lockName()))
+	return int64(h.Sum64())
+}
+
+// bind returns the placeholder of the nth argument.
+func (m *Migrator) bind(n int) string {
+	if m.dialect == "postgres" {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
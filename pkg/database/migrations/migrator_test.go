@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"boilerplate/pkg/database/sqlite"
+)
+
+func TestMigrator_sqlite(t *testing.T) {
+	db, err := sqlite.Config{Name: t.Name(), Path: sqlite.Memory}.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	ctx := context.Background()
+	for _, connection := range Connections() {
+		m, err := New(connection, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// twice, to check the down migrations revert the up ones
+		for i := 0; i < 2; i++ {
+			if _, err := m.Up(ctx, 0); err != nil {
+				t.Fatalf("Up() error = %v", err)
+			}
+			status, err := m.Status(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range status {
+				if s.AppliedAt == nil {
+					t.Errorf("%d_%s of %s is not applied", s.Version, s.Name, connection)
+				}
+			}
+			if _, err := m.Down(ctx, len(m.migrations)); err != nil {
+				t.Fatalf("Down() error = %v", err)
+			}
+		}
+	}
+}
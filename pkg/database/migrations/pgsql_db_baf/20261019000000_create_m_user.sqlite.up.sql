@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS m_user (
+    id            INTEGER      PRIMARY KEY AUTOINCREMENT,
+    username      VARCHAR(255) NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    password      VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL,
+    role_id       INTEGER      NOT NULL,
+    is_active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_date  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    INTEGER      NOT NULL DEFAULT 0,
+    modified_date DATETIME,
+    modified_by   INTEGER
+);
@@ -0,0 +1,15 @@
+-- m_user predates the versioned migrations, databases that already have it
+-- only get the version recorded
+CREATE TABLE IF NOT EXISTS m_user (
+    id            SERIAL PRIMARY KEY,
+    username      VARCHAR(255) NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    password      VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL,
+    role_id       INTEGER      NOT NULL,
+    is_active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_date  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by    INTEGER      NOT NULL DEFAULT 0,
+    modified_date TIMESTAMPTZ,
+    modified_by   INTEGER
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS t_audit_log (
+    id           BIGSERIAL PRIMARY KEY,
+    entity       VARCHAR(64)  NOT NULL,
+    entity_id    VARCHAR(64)  NOT NULL,
+    action       VARCHAR(16)  NOT NULL,
+    before       JSONB,
+    after        JSONB,
+    changes      JSONB,
+    actor_id     INTEGER,
+    request_id   VARCHAR(64)  NOT NULL DEFAULT '',
+    ip_address   VARCHAR(64)  NOT NULL DEFAULT '',
+    created_date TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_t_audit_log_entity ON t_audit_log (entity, entity_id, id DESC);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_m_user_email_trgm;
+DROP INDEX IF EXISTS idx_m_user_username_trgm;
+DROP INDEX IF EXISTS idx_m_user_name_trgm;
+DROP INDEX IF EXISTS idx_m_user_search_vector;
+
+ALTER TABLE m_user DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1 @@
+ALTER TABLE m_user DROP COLUMN search_vector;
@@ -0,0 +1,5 @@
+-- SQLite has no full-text search vector, the searched text is matched with
+-- LIKE instead, see dialect.WebSearch
+ALTER TABLE m_user ADD COLUMN search_vector TEXT GENERATED ALWAYS AS (
+    LOWER(COALESCE(name, '') || ' ' || COALESCE(username, '') || ' ' || COALESCE(email, ''))
+) VIRTUAL;
@@ -0,0 +1,12 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE m_user ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(username, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(email, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_m_user_search_vector ON m_user USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_m_user_name_trgm ON m_user USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_m_user_username_trgm ON m_user USING GIN (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_m_user_email_trgm ON m_user USING GIN (email gin_trgm_ops);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_m_user_role_id;
+
+ALTER TABLE m_user DROP CONSTRAINT IF EXISTS fk_m_user_role_id;
+
+DROP TABLE IF EXISTS m_role;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_m_user_role_id;
+
+DROP TABLE IF EXISTS m_role;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS m_role (
+    id            INTEGER      PRIMARY KEY AUTOINCREMENT,
+    name          VARCHAR(64)  NOT NULL,
+    description   TEXT,
+    created_date  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    INTEGER      NOT NULL DEFAULT 0,
+    modified_date DATETIME,
+    modified_by   INTEGER
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_role_name ON m_role (LOWER(name));
+
+INSERT OR IGNORE INTO m_role (id, name)
+SELECT DISTINCT role_id, 'Role ' || role_id FROM m_user;
+
+-- SQLite can't add a foreign key to an existing table
+CREATE INDEX IF NOT EXISTS idx_m_user_role_id ON m_user (role_id);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS m_role (
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(64)  NOT NULL,
+    description   TEXT,
+    created_date  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by    INTEGER      NOT NULL DEFAULT 0,
+    modified_date TIMESTAMPTZ,
+    modified_by   INTEGER
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_role_name ON m_role (LOWER(name));
+
+-- keep the roles users already reference so the foreign key can be added
+INSERT INTO m_role (id, name)
+SELECT DISTINCT role_id, 'Role ' || role_id FROM m_user
+ON CONFLICT (id) DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('m_role', 'id'), GREATEST((SELECT MAX(id) FROM m_role), 1));
+
+ALTER TABLE m_user
+    ADD CONSTRAINT fk_m_user_role_id FOREIGN KEY (role_id) REFERENCES m_role (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_m_user_role_id ON m_user (role_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS m_user_group;
+
+DROP TABLE IF EXISTS m_group;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS m_group (
+    id            INTEGER      PRIMARY KEY AUTOINCREMENT,
+    name          VARCHAR(128) NOT NULL,
+    type          VARCHAR(32)  NOT NULL DEFAULT '',
+    description   TEXT,
+    created_date  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    INTEGER      NOT NULL DEFAULT 0,
+    modified_date DATETIME,
+    modified_by   INTEGER
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_group_name ON m_group (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS m_user_group (
+    user_id      INTEGER  NOT NULL REFERENCES m_user (id) ON DELETE CASCADE,
+    group_id     INTEGER  NOT NULL REFERENCES m_group (id) ON DELETE CASCADE,
+    created_date DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by   INTEGER  NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, group_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_m_user_group_group_id ON m_user_group (group_id);
@@ -0,0 +1,9 @@
+ALTER TABLE m_role DROP COLUMN is_global;
+
+DROP INDEX IF EXISTS idx_m_user_org_unit_id;
+
+ALTER TABLE m_user DROP COLUMN org_unit_id;
+
+DROP TABLE IF EXISTS m_org_unit_closure;
+
+DROP TABLE IF EXISTS m_org_unit;
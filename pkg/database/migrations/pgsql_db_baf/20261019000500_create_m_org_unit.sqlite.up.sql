@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS m_org_unit (
+    id            INTEGER      PRIMARY KEY AUTOINCREMENT,
+    parent_id     INTEGER REFERENCES m_org_unit (id) ON DELETE RESTRICT,
+    code          VARCHAR(32)  NOT NULL DEFAULT '',
+    name          VARCHAR(128) NOT NULL,
+    created_date  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    INTEGER      NOT NULL DEFAULT 0,
+    modified_date DATETIME,
+    modified_by   INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_m_org_unit_parent_id ON m_org_unit (parent_id);
+
+CREATE TABLE IF NOT EXISTS m_org_unit_closure (
+    ancestor_id   INTEGER NOT NULL REFERENCES m_org_unit (id) ON DELETE CASCADE,
+    descendant_id INTEGER NOT NULL REFERENCES m_org_unit (id) ON DELETE CASCADE,
+    depth         INTEGER NOT NULL,
+    PRIMARY KEY (ancestor_id, descendant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_m_org_unit_closure_descendant_id ON m_org_unit_closure (descendant_id);
+
+-- without a foreign key, SQLite can't drop a column referencing a table
+ALTER TABLE m_user ADD COLUMN org_unit_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_m_user_org_unit_id ON m_user (org_unit_id);
+
+ALTER TABLE m_role ADD COLUMN is_global BOOLEAN NOT NULL DEFAULT FALSE;
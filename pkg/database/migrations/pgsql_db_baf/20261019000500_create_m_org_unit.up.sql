@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS m_org_unit (
+    id            SERIAL PRIMARY KEY,
+    parent_id     INTEGER REFERENCES m_org_unit (id) ON DELETE RESTRICT,
+    code          VARCHAR(32)  NOT NULL DEFAULT '',
+    name          VARCHAR(128) NOT NULL,
+    created_date  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by    INTEGER      NOT NULL DEFAULT 0,
+    modified_date TIMESTAMPTZ,
+    modified_by   INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_m_org_unit_parent_id ON m_org_unit (parent_id);
+
+CREATE TABLE IF NOT EXISTS m_org_unit_closure (
+    ancestor_id   INTEGER NOT NULL REFERENCES m_org_unit (id) ON DELETE CASCADE,
+    descendant_id INTEGER NOT NULL REFERENCES m_org_unit (id) ON DELETE CASCADE,
+    depth         INTEGER NOT NULL,
+    PRIMARY KEY (ancestor_id, descendant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_m_org_unit_closure_descendant_id ON m_org_unit_closure (descendant_id);
+
+ALTER TABLE m_user ADD COLUMN IF NOT EXISTS org_unit_id INTEGER REFERENCES m_org_unit (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_m_user_org_unit_id ON m_user (org_unit_id);
+
+ALTER TABLE m_role ADD COLUMN IF NOT EXISTS is_global BOOLEAN NOT NULL DEFAULT FALSE;
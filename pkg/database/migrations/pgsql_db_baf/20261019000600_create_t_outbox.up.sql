@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS t_outbox (
+    id                BIGSERIAL PRIMARY KEY,
+    event_id          UUID         NOT NULL,
+    event_type        VARCHAR(128) NOT NULL,
+    aggregate_type    VARCHAR(64)  NOT NULL,
+    aggregate_id      VARCHAR(64)  NOT NULL,
+    payload           JSONB        NOT NULL,
+    actor_id          INTEGER,
+    request_id        VARCHAR(64)  NOT NULL DEFAULT '',
+    status            VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    attempts          INTEGER      NOT NULL DEFAULT 0,
+    next_attempt_date TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_error        TEXT         NOT NULL DEFAULT '',
+    delivered_date    TIMESTAMPTZ,
+    created_date      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_t_outbox_event_id ON t_outbox (event_id);
+
+-- the dispatcher only scans pending events
+CREATE INDEX IF NOT EXISTS idx_t_outbox_pending ON t_outbox (next_attempt_date, id) WHERE status = 'pending';
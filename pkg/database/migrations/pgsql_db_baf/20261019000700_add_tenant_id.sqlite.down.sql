@@ -0,0 +1,17 @@
+ALTER TABLE t_outbox DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_t_audit_log_tenant_id;
+ALTER TABLE t_audit_log DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS uq_m_group_name;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_group_name ON m_group (LOWER(name));
+DROP INDEX IF EXISTS uq_m_role_name;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_role_name ON m_role (LOWER(name));
+
+DROP INDEX IF EXISTS idx_m_org_unit_tenant_id;
+DROP INDEX IF EXISTS idx_m_user_tenant_id;
+
+ALTER TABLE m_org_unit DROP COLUMN tenant_id;
+ALTER TABLE m_group DROP COLUMN tenant_id;
+ALTER TABLE m_role DROP COLUMN tenant_id;
+ALTER TABLE m_user DROP COLUMN tenant_id;
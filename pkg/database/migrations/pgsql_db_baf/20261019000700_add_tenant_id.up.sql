@@ -0,0 +1,25 @@
+-- rows created before tenancy belong to the default tenant
+ALTER TABLE m_user ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE m_role ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE m_group ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE m_org_unit ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+
+-- new rows are stamped by the application
+ALTER TABLE m_user ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE m_role ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE m_group ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE m_org_unit ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_m_user_tenant_id ON m_user (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_m_org_unit_tenant_id ON m_org_unit (tenant_id);
+
+-- names are unique per tenant
+DROP INDEX IF EXISTS uq_m_role_name;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_role_name ON m_role (tenant_id, LOWER(name));
+DROP INDEX IF EXISTS uq_m_group_name;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_group_name ON m_group (tenant_id, LOWER(name));
+
+ALTER TABLE t_audit_log ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_t_audit_log_tenant_id ON t_audit_log (tenant_id, id DESC);
+
+ALTER TABLE t_outbox ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
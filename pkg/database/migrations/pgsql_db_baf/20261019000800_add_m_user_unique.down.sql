@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_m_user_email;
+DROP INDEX IF EXISTS uq_m_user_username;
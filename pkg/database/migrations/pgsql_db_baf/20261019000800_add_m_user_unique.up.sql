@@ -0,0 +1,4 @@
+-- usernames and emails identify users at login across the tenants, the
+-- service relies on these indexes instead of checking beforehand
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_user_username ON m_user (username);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_m_user_email ON m_user (email);
@@ -3,13 +3,9 @@ package msql
 import (
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
 	"time"
 
-	"golang.org/x/crypto/ssh"
 	"gorm.io/driver/mysql"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/plugin/dbresolver"
@@ -22,34 +18,47 @@ type DBConfig struct {
 	Port string
 	Name string
 
-	ParseTime string
+	ParseTime bool
 
-	SSHClient *ssh.Client
+	// Tunnel dials the node through SSH when set.
+	Tunnel Dialer
 }
 
 // Config ...
 type Config struct {
+	Name  string
 	Write DBConfig
 	Read  []DBConfig
-}
 
-var dblogger = logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
-	SlowThreshold:             200 * time.Millisecond,
-	LogLevel:                  logger.Warn,
-	IgnoreRecordNotFoundError: false,
-	Colorful:                  true,
-})
+	// the pool settings of the replicas, the caller sets those of the primary
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectTimeout  time.Duration
+
+	// Logger logs the statements, the default logger of GORM when nil.
+	Logger logger.Interface
+}
 
 // DSN ...
 func (c Config) DSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=%s", c.Write.User, c.Write.Pass, c.Write.Host, c.Write.Port, c.Write.Name, c.Write.ParseTime)
+	return c.dsn(c.Write)
+}
+
+func (c Config) dsn(node DBConfig) string {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=%t", node.User, node.Pass, node.Host, node.Port, node.Name, node.ParseTime)
+	if c.ConnectTimeout > 0 {
+		dsn += "&timeout=" + c.ConnectTimeout.String()
+	}
+	return dsn
 }
 
 // Open ...
 func (c Config) Open() (*gorm.DB, error) {
 	dialector := mysql.Open(c.DSN())
-	if c.Write.SSHClient != nil {
-		driverName := fmt.Sprintf("mysql+ssh+%s+%s+write", c.Write.SSHClient.LocalAddr().Network(), c.Write.SSHClient.LocalAddr().String())
+	if c.Write.Tunnel != nil {
+		driverName := fmt.Sprintf("mysql+ssh+%s+write", c.Name)
 
 		found := false
 		for _, d := range sql.Drivers() {
@@ -59,7 +68,7 @@ func (c Config) Open() (*gorm.DB, error) {
 		}
 		if !found {
 			// Now we register the ViaSSHDialer with the ssh connection as a parameter
-			sql.Register(driverName, &ViaSSHDialer{Client: c.Write.SSHClient})
+			sql.Register(driverName, &ViaSSHDialer{Client: c.Write.Tunnel})
 		}
 
 		dialector = mysql.New(mysql.Config{
@@ -69,11 +78,17 @@ func (c Config) Open() (*gorm.DB, error) {
 	}
 
 	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger:                 dblogger.LogMode(logger.Info),
+		Logger:                 c.Logger,
 		SkipDefaultTransaction: true,
 		PrepareStmt:            true,
 	})
 	if err != nil {
+		// the pool is opened before the ping failing
+		if db != nil {
+			if sqlDB, errDB := db.DB(); errDB == nil {
+				_ = sqlDB.Close()
+			}
+		}
 		return nil, err
 	}
 
@@ -81,10 +96,10 @@ func (c Config) Open() (*gorm.DB, error) {
 		var replica []gorm.Dialector
 
 		for i, config := range c.Read {
-			dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=%s", config.User, config.Pass, config.Host, config.Port, config.Name, config.ParseTime)
-			dialector := postgres.Open(dsn)
-			if config.SSHClient != nil {
-				driverName := fmt.Sprintf("mysql+ssh+%s+%s+read_%d", config.SSHClient.LocalAddr().Network(), config.SSHClient.LocalAddr().String(), i)
+			dsn := c.dsn(config)
+			dialector := mysql.Open(dsn)
+			if config.Tunnel != nil {
+				driverName := fmt.Sprintf("mysql+ssh+%s+read_%d", c.Name, i)
 
 				found := false
 				for _, d := range sql.Drivers() {
@@ -94,7 +109,7 @@ func (c Config) Open() (*gorm.DB, error) {
 				}
 				if !found {
 					// Now we register the ViaSSHDialer with the ssh connection as a parameter
-					sql.Register(driverName, &ViaSSHDialer{Client: config.SSHClient})
+					sql.Register(driverName, &ViaSSHDialer{Client: config.Tunnel})
 				}
 
 				dialector = mysql.New(mysql.Config{
@@ -109,11 +124,14 @@ func (c Config) Open() (*gorm.DB, error) {
 			Replicas:          replica,
 			TraceResolverMode: true,
 		}).
-			SetConnMaxIdleTime(time.Hour).       // SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
-			SetConnMaxLifetime(5 * time.Minute). // SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
-			SetMaxIdleConns(5).                  // SetMaxIdleConns sets the maximum number of connections in the idle connection pool.
-			SetMaxOpenConns(10),
+			SetConnMaxIdleTime(c.ConnMaxIdleTime). // SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
+			SetConnMaxLifetime(c.ConnMaxLifetime). // SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
+			SetMaxIdleConns(c.MaxIdleConns).       // SetMaxIdleConns sets the maximum number of connections in the idle connection pool.
+			SetMaxOpenConns(c.MaxOpenConns),
 		); err != nil {
+			if sqlDB, errDB := db.DB(); errDB == nil {
+				_ = sqlDB.Close()
+			}
 			return nil, err
 		}
 	}
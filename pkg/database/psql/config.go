@@ -3,11 +3,10 @@ package psql
 import (
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
 	"time"
 
-	"golang.org/x/crypto/ssh"
+	"boilerplate/pkg/database/replica"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -24,32 +23,50 @@ type DBConfig struct {
 	SSLMode string
 	TZ      string
 
-	SSHClient *ssh.Client
+	// Tunnel dials the node through SSH when set.
+	Tunnel Dialer
 }
 
 // Config ...
 type Config struct {
+	Name  string
 	Write DBConfig
 	Read  []DBConfig
-}
 
-var dblogger = logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
-	SlowThreshold:             200 * time.Millisecond,
-	LogLevel:                  logger.Warn,
-	IgnoreRecordNotFoundError: false,
-	Colorful:                  true,
-})
+	// MaxLag and LagCheckInterval configure the health checks of the Read
+	// replicas, see replica.Set.
+	MaxLag           time.Duration
+	LagCheckInterval time.Duration
+
+	// the pool settings of the replicas, the caller sets those of the primary
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectTimeout  time.Duration
+
+	// Logger logs the statements, the default logger of GORM when nil.
+	Logger logger.Interface
+}
 
 // DSN ...
 func (c Config) DSN() string {
-	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s", c.Write.Host, c.Write.User, c.Write.Pass, c.Write.Name, c.Write.Port, c.Write.SSLMode, c.Write.TZ)
+	return c.dsn(c.Write)
+}
+
+func (c Config) dsn(node DBConfig) string {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s", node.Host, node.User, node.Pass, node.Name, node.Port, node.SSLMode, node.TZ)
+	if c.ConnectTimeout > 0 {
+		dsn += fmt.Sprintf(" connect_timeout=%d", int(c.ConnectTimeout.Seconds()))
+	}
+	return dsn
 }
 
 // Open ...
 func (c Config) Open() (*gorm.DB, error) {
 	dialector := postgres.Open(c.DSN())
-	if c.Write.SSHClient != nil {
-		driverName := fmt.Sprintf("postgres+ssh+%s+write", c.Write.SSHClient.LocalAddr().String())
+	if c.Write.Tunnel != nil {
+		driverName := fmt.Sprintf("postgres+ssh+%s+write", c.Name)
 
 		found := false
 		for _, d := range sql.Drivers() {
@@ -59,7 +76,7 @@ func (c Config) Open() (*gorm.DB, error) {
 		}
 		if !found {
 			// Now we register the ViaSSHDialer with the ssh connection as a parameter
-			sql.Register(driverName, &ViaSSHDialer{Client: c.Write.SSHClient})
+			sql.Register(driverName, &ViaSSHDialer{Client: c.Write.Tunnel})
 		}
 
 		dialector = postgres.New(postgres.Config{
@@ -68,23 +85,56 @@ func (c Config) Open() (*gorm.DB, error) {
 		})
 	}
 
+	// the primary is pinged below rather than by gorm, dbresolver opens the
+	// replicas with the same config and they may be down
 	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger:                 dblogger.LogMode(logger.Info),
+		Logger:                 c.Logger,
 		SkipDefaultTransaction: true,
 		PrepareStmt:            true,
+		DisableAutomaticPing:   true,
 	})
 	if err != nil {
+		if db != nil {
+			if sqlDB, errDB := db.DB(); errDB == nil {
+				_ = sqlDB.Close()
+			}
+		}
+		return nil, err
+	}
+	if sqlDB, errDB := db.DB(); errDB != nil {
+		return nil, errDB
+	} else if err = sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
 		return nil, err
 	}
 
 	if len(c.Read) > 0 {
-		var replica []gorm.Dialector
+		var (
+			replicas []gorm.Dialector
+			dbs      []*sql.DB
+			set      *replica.Set
+		)
+		// close the pools opened so far, the dependency manager opens the
+		// connection again on its next attempt
+		fail := func(err error) (*gorm.DB, error) {
+			if set != nil {
+				_ = set.Close()
+			} else {
+				for _, sqlDB := range dbs {
+					_ = sqlDB.Close()
+				}
+			}
+			if sqlDB, errDB := db.DB(); errDB == nil {
+				_ = sqlDB.Close()
+			}
+			return nil, err
+		}
 
 		for i, config := range c.Read {
-			dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s", config.Host, config.User, config.Pass, config.Name, config.Port, config.SSLMode, config.TZ)
+			dsn := c.dsn(config)
 			dialector := postgres.Open(dsn)
-			if config.SSHClient != nil {
-				driverName := fmt.Sprintf("postgres+ssh+%s+read_%d", config.SSHClient.LocalAddr().String(), i)
+			if config.Tunnel != nil {
+				driverName := fmt.Sprintf("postgres+ssh+%s+read_%d", c.Name, i)
 
 				found := false
 				for _, d := range sql.Drivers() {
@@ -94,7 +144,7 @@ func (c Config) Open() (*gorm.DB, error) {
 				}
 				if !found {
 					// Now we register the ViaSSHDialer with the ssh connection as a parameter
-					sql.Register(driverName, &ViaSSHDialer{Client: config.SSHClient})
+					sql.Register(driverName, &ViaSSHDialer{Client: config.Tunnel})
 				}
 
 				dialector = postgres.New(postgres.Config{
@@ -102,19 +152,38 @@ func (c Config) Open() (*gorm.DB, error) {
 					DSN:        dsn,
 				})
 			}
-			replica = append(replica, dialector)
+
+			// open the pool here so the replica set can check its lag, without
+			// the ping: an unreachable replica is left out by the set instead
+			// of failing the connection
+			replicaDB, err := gorm.Open(dialector, &gorm.Config{Logger: c.Logger, DisableAutomaticPing: true})
+			if err != nil {
+				return fail(err)
+			}
+			sqlDB, err := replicaDB.DB()
+			if err != nil {
+				return fail(err)
+			}
+			dbs = append(dbs, sqlDB)
+			replicas = append(replicas, postgres.New(postgres.Config{Conn: sqlDB}))
 		}
 
+		set = replica.NewSet(c.Name, dbs, c.MaxLag, c.LagCheckInterval, replica.LagQuery)
 		if err = db.Use(dbresolver.Register(dbresolver.Config{
-			Replicas:          replica,
+			Replicas:          replicas,
+			Policy:            set,
 			TraceResolverMode: true,
 		}).
-			SetConnMaxIdleTime(time.Hour).       // SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
-			SetConnMaxLifetime(5 * time.Minute). // SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
-			SetMaxIdleConns(5).                  // SetMaxIdleConns sets the maximum number of connections in the idle connection pool.
-			SetMaxOpenConns(10),
+			SetConnMaxIdleTime(c.ConnMaxIdleTime). // SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
+			SetConnMaxLifetime(c.ConnMaxLifetime). // SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
+			SetMaxIdleConns(c.MaxIdleConns).       // SetMaxIdleConns sets the maximum number of connections in the idle connection pool.
+			SetMaxOpenConns(c.MaxOpenConns),
 		); err != nil {
-			return nil, err
+			return fail(err)
+		}
+		// registered last so that dbresolver doesn't copy it to the replicas
+		if err = db.Use(set); err != nil {
+			return fail(err)
 		}
 	}
 	return db, nil
@@ -6,12 +6,16 @@ import (
 
 	"database/sql/driver"
 	"github.com/lib/pq"
-	"golang.org/x/crypto/ssh"
 )
 
+// Dialer opens connections through an SSH tunnel.
+type Dialer interface {
+	Dial(network, addr string) (net.Conn, error)
+}
+
 // ViaSSHDialer ...
 type ViaSSHDialer struct {
-	Client *ssh.Client
+	Client Dialer
 }
 
 // Open ...
@@ -0,0 +1,162 @@
+// Package querylog logs the statements of GORM as JSON through logrus, with
+// the request and the caller they were made for, and counts them per request
+// with Stats.
+package querylog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"boilerplate/internal/abstraction"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Config ...
+type Config struct {
+	// Level is the least severe statement logged: every statement at
+	// logger.Info, the slow ones at logger.Warn, the failed ones at
+	// logger.Error. It defaults to logger.Warn.
+	Level logger.LogLevel
+	// SlowThreshold is the duration above which a statement is slow, zero
+	// disables the slow statements.
+	SlowThreshold time.Duration
+	// Params logs the parameters of the statements, which are redacted
+	// otherwise as they may hold passwords or personal data.
+	Params bool
+	// Output is the logrus logger written to, a JSON logger on stdout when
+	// nil.
+	Output *logrus.Logger
+}
+
+// Logger is a logger.Interface writing the statements of a connection.
+type Logger struct {
+	config Config
+	entry  *logrus.Entry
+}
+
+var output = &logrus.Logger{
+	Out:       os.Stdout,
+	Formatter: &logrus.JSONFormatter{},
+	Hooks:     make(logrus.LevelHooks),
+	Level:     logrus.InfoLevel,
+}
+
+// New returns the logger of the statements of connection.
+func New(connection string, config Config) *Logger {
+	out := config.Output
+	if out == nil {
+		out = output
+	}
+	if config.Level == 0 {
+		config.Level = logger.Warn
+	}
+	return &Logger{
+		config: config,
+		entry:  out.WithField("connection", connection),
+	}
+}
+
+// LogMode ...
+func (l *Logger) LogMode(level logger.LogLevel) logger.Interface {
+	nl := *l
+	nl.config.Level = level
+	return &nl
+}
+
+// Info ...
+func (l *Logger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.Level >= logger.Info {
+		l.with(ctx).Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn ...
+func (l *Logger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.Level >= logger.Warn {
+		l.with(ctx).Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error ...
+func (l *Logger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.Level >= logger.Error {
+		l.with(ctx).Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace logs the statement run since begin and adds it to the Stats of ctx.
+// A statement finding no record isn't an error.
+func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	if stats := StatsFrom(ctx); stats != nil {
+		stats.add(elapsed)
+	}
+	if l.config.Level <= logger.Silent {
+		return
+	}
+
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := l.config.SlowThreshold > 0 && elapsed > l.config.SlowThreshold
+	switch {
+	case failed && l.config.Level >= logger.Error:
+	case slow && l.config.Level >= logger.Warn:
+	case l.config.Level >= logger.Info:
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	entry := l.with(ctx).WithFields(logrus.Fields{
+		"sql":         sql,
+		"duration_ms": float64(elapsed.Microseconds()) / 1000,
+	})
+	// -1 when the driver doesn't report it
+	if rows >= 0 {
+		entry = entry.WithField("rows", rows)
+	}
+
+	switch {
+	case failed:
+		entry.WithError(err).Error("query failed")
+	case slow:
+		entry.WithField("slow_threshold_ms", l.config.SlowThreshold.Milliseconds()).Warn("slow query")
+	default:
+		entry.Info("query")
+	}
+}
+
+// ParamsFilter leaves the placeholders of the statement in the log instead of
+// its parameters, unless Config.Params is set. GORM doesn't filter the
+// statements of DB.Scan, use DB.Find instead.
+func (l *Logger) ParamsFilter(_ context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.config.Params {
+		return sql, params
+	}
+	return sql, nil
+}
+
+// with adds the request and the caller of ctx to the entry. The *Context is
+// missing from the contexts taken straight from the request, which only have
+// its Stats.
+func (l *Logger) with(ctx context.Context) *logrus.Entry {
+	entry := l.entry
+	if c, ok := abstraction.FromContext(ctx); ok {
+		if id := c.RequestID(); id != "" {
+			entry = entry.WithField("request_id", id)
+		}
+		if c.Auth != nil {
+			entry = entry.WithField("user_id", c.Auth.ID)
+		}
+		return entry
+	}
+	if stats := StatsFrom(ctx); stats != nil && stats.RequestID != "" {
+		entry = entry.WithField("request_id", stats.RequestID)
+	}
+	return entry
+}
@@ -0,0 +1,112 @@
+package querylog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		query  string
+		want   []map[string]interface{}
+	}{
+		{
+			name:   "should log every statement redacted at info",
+			config: Config{Level: logger.Info},
+			query:  "SELECT ? AS secret",
+			want: []map[string]interface{}{
+				{"level": "info", "msg": "query", "sql": "SELECT ? AS secret", "rows": float64(1), "request_id": "req-1", "connection": "TEST"},
+			},
+		},
+		{
+			name:   "should log the parameters when asked",
+			config: Config{Level: logger.Info, Params: true},
+			query:  "SELECT ? AS secret",
+			want: []map[string]interface{}{
+				{"level": "info", "sql": `SELECT "hunter2" AS secret`},
+			},
+		},
+		{
+			name:   "should log nothing but slow statements at warn",
+			config: Config{Level: logger.Warn, SlowThreshold: time.Hour},
+			query:  "SELECT ? AS secret",
+		},
+		{
+			name:   "should log slow statements",
+			config: Config{Level: logger.Warn, SlowThreshold: time.Nanosecond},
+			query:  "SELECT ? AS secret",
+			want: []map[string]interface{}{
+				{"level": "warning", "msg": "slow query", "slow_threshold_ms": float64(0)},
+			},
+		},
+		{
+			name:   "should log failed statements",
+			config: Config{Level: logger.Error},
+			query:  "SELECT ? FROM missing",
+			want: []map[string]interface{}{
+				{"level": "error", "msg": "query failed", "sql": "SELECT ? FROM missing"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.config.Output = &logrus.Logger{Out: &buf, Formatter: &logrus.JSONFormatter{}, Hooks: make(logrus.LevelHooks), Level: logrus.InfoLevel}
+
+			db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{Logger: New("TEST", tt.config)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, stats := WithStats(context.Background(), "req-1")
+			var rows []map[string]interface{}
+			db.WithContext(ctx).Raw(tt.query, "hunter2").Find(&rows)
+
+			if stats.Queries() != 1 || stats.Duration() <= 0 {
+				t.Errorf("stats = %d queries in %s, want 1", stats.Queries(), stats.Duration())
+			}
+
+			var lines []string
+			if s := strings.TrimSpace(buf.String()); s != "" {
+				lines = strings.Split(s, "\n")
+			}
+			if len(lines) != len(tt.want) {
+				t.Fatalf("logged %q, want %d lines", lines, len(tt.want))
+			}
+			for i, line := range lines {
+				if strings.Contains(line, "hunter2") != tt.config.Params {
+					t.Errorf("line %q, params logged = %t", line, tt.config.Params)
+				}
+				var got map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &got); err != nil {
+					t.Fatal(err)
+				}
+				for k, v := range tt.want[i] {
+					if got[k] != v {
+						t.Errorf("%s = %v, want %v", k, got[k], v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStats_ServerTiming(t *testing.T) {
+	_, stats := WithStats(context.Background(), "")
+	stats.add(1500 * time.Microsecond)
+	stats.add(2 * time.Millisecond)
+
+	if got, want := stats.ServerTiming(), `db;dur=3.5;desc="2 queries"`; got != want {
+		t.Errorf("ServerTiming() = %s, want %s", got, want)
+	}
+}
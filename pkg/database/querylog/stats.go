@@ -0,0 +1,55 @@
+package querylog
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+type statsKey struct{}
+
+// Stats counts the statements of a request and the time spent running them,
+// across the connections and the goroutines of the request.
+type Stats struct {
+	RequestID string
+
+	queries  atomic.Int64
+	duration atomic.Int64
+}
+
+// WithStats returns ctx carrying new Stats of the request requestID.
+func WithStats(ctx context.Context, requestID string) (context.Context, *Stats) {
+	stats := &Stats{RequestID: requestID}
+	return context.WithValue(ctx, statsKey{}, stats), stats
+}
+
+// StatsFrom returns the Stats stored by WithStats, nil when there are none.
+func StatsFrom(ctx context.Context) *Stats {
+	if ctx == nil {
+		return nil
+	}
+	stats, _ := ctx.Value(statsKey{}).(*Stats)
+	return stats
+}
+
+func (s *Stats) add(elapsed time.Duration) {
+	s.queries.Add(1)
+	s.duration.Add(int64(elapsed))
+}
+
+// Queries returns the number of statements run.
+func (s *Stats) Queries() int64 {
+	return s.queries.Load()
+}
+
+// Duration returns the total time spent running the statements.
+func (s *Stats) Duration() time.Duration {
+	return time.Duration(s.duration.Load())
+}
+
+// ServerTiming returns the Server-Timing metric of the statements, e.g.
+// db;dur=12.5;desc="4 queries".
+func (s *Stats) ServerTiming() string {
+	return fmt.Sprintf(`db;dur=%.1f;desc="%d queries"`, float64(s.Duration().Microseconds())/1000, s.Queries())
+}
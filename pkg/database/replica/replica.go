@@ -0,0 +1,183 @@
+// Package replica keeps track of the replication lag of read replicas and
+// routes reads made outside a transaction to the healthy ones.
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	pluginName   = "replica"
+	checkTimeout = 2 * time.Second
+)
+
+// LagQuery returns the replication lag of a PostgreSQL standby in seconds. A
+// standby that replayed everything it received is not lagging, even when the
+// primary has been idle for a while.
+const LagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// Set is the dbresolver.Policy of a connection's replicas. Replicas whose lag
+// is above MaxLag, or that can't be reached, are left out until they catch up.
+// It is registered as a gorm plugin so that FromDB finds it.
+type Set struct {
+	name     string
+	dbs      []*sql.DB
+	healthy  []atomic.Bool
+	maxLag   time.Duration
+	interval time.Duration
+	lagQuery string
+
+	startOnce sync.Once
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewSet watches dbs, checking their lag with lagQuery every interval.
+func NewSet(name string, dbs []*sql.DB, maxLag, interval time.Duration, lagQuery string) *Set {
+	return &Set{
+		name:     name,
+		dbs:      dbs,
+		healthy:  make([]atomic.Bool, len(dbs)),
+		maxLag:   maxLag,
+		interval: interval,
+		lagQuery: lagQuery,
+		stop:     make(chan struct{}),
+	}
+}
+
+// FromDB returns the Set registered on db, if any.
+func FromDB(db *gorm.DB) *Set {
+	if db == nil {
+		return nil
+	}
+	s, _ := db.Config.Plugins[pluginName].(*Set)
+	return s
+}
+
+func (s *Set) Name() string {
+	return pluginName
+}
+
+// Initialize checks the replicas once and keeps checking them in the
+// background until Close. It only starts once, gorm initializes plugins again
+// on sessions opened with a copy of the config.
+func (s *Set) Initialize(*gorm.DB) error {
+	s.startOnce.Do(func() {
+		s.check()
+		healthy := 0
+		for i := range s.healthy {
+			if s.healthy[i].Load() {
+				healthy++
+			}
+		}
+		logrus.Infof("%d of %d replicas of db %s are serving reads", healthy, len(s.dbs), s.name)
+		go s.watch()
+	})
+	return nil
+}
+
+// Resolve picks a healthy replica at random, or any replica when none is
+// healthy; callers check Available first to fall back to the primary.
+func (s *Set) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	healthy := make([]gorm.ConnPool, 0, len(pools))
+	for _, pool := range pools {
+		if s.isHealthy(pool) {
+			healthy = append(healthy, pool)
+		}
+	}
+	if len(healthy) == 0 {
+		return pools[rand.Intn(len(pools))]
+	}
+	return healthy[rand.Intn(len(healthy))]
+}
+
+// Available reports whether at least one replica can serve reads.
+func (s *Set) Available() bool {
+	if s == nil {
+		return false
+	}
+	for i := range s.healthy {
+		if s.healthy[i].Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops the checks and closes the replica connections.
+func (s *Set) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		for _, db := range s.dbs {
+			if errClose := db.Close(); errClose != nil {
+				err = errClose
+			}
+		}
+	})
+	return err
+}
+
+func (s *Set) isHealthy(pool gorm.ConnPool) bool {
+	for i, db := range s.dbs {
+		if gorm.ConnPool(db) == pool {
+			return s.healthy[i].Load()
+		}
+	}
+	return false
+}
+
+func (s *Set) watch() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.check()
+		}
+	}
+}
+
+func (s *Set) check() {
+	for i, db := range s.dbs {
+		log := logrus.WithFields(logrus.Fields{"db": s.name, "replica": i})
+
+		lag, err := s.lag(db)
+		healthy := err == nil && lag <= s.maxLag
+		if was := s.healthy[i].Swap(healthy); was == healthy {
+			continue
+		}
+		switch {
+		case err != nil:
+			log.WithError(err).Warn("replica is unreachable, reads go to the primary")
+		case !healthy:
+			log.WithField("lag", lag).Warn("replica is lagging, reads go to the primary")
+		default:
+			log.WithField("lag", lag).Info("replica is serving reads")
+		}
+	}
+}
+
+func (s *Set) lag(db *sql.DB) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	var seconds float64
+	if err := db.QueryRowContext(ctx, s.lagQuery).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,79 @@
+package replica
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/pkg/redis"
+
+	goRedis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const cookieName = "read_primary_until"
+
+// Router implements abstraction.ReadConsistency. After a write the caller is
+// pinned to the primary for Window: authenticated callers through a Redis key
+// shared by every instance, anonymous ones through a cookie.
+type Router struct {
+	Replicas *Set
+	Redis    *goRedis.Client
+	Window   time.Duration
+}
+
+func NewRouter(replicas *Set, redisClient *goRedis.Client, window time.Duration) *Router {
+	return &Router{
+		Replicas: replicas,
+		Redis:    redisClient,
+		Window:   window,
+	}
+}
+
+func (r *Router) ReplicaAvailable() bool {
+	return r.Replicas.Available()
+}
+
+func (r *Router) Stale(c *abstraction.Context) bool {
+	if c.Auth != nil && r.Redis != nil {
+		n, err := r.Redis.Exists(c.Request().Context(), key(c)).Result()
+		if err != nil {
+			// when in doubt read from the primary
+			logrus.WithError(err).Warn("failed to read the read-your-writes marker")
+			return true
+		}
+		return n > 0
+	}
+
+	cookie, err := c.Cookie(cookieName)
+	if err != nil {
+		return false
+	}
+	until, err := strconv.ParseInt(cookie.Value, 10, 64)
+	return err == nil && time.Now().UnixMilli() < until
+}
+
+func (r *Router) MarkWrite(c *abstraction.Context) {
+	if c.Auth != nil && r.Redis != nil {
+		// the request may be done by the time the marker is written
+		if err := r.Redis.Set(context.WithoutCancel(c.Request().Context()), key(c), 1, r.Window).Err(); err != nil {
+			logrus.WithError(err).Warn("failed to write the read-your-writes marker")
+		}
+		return
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     cookieName,
+		Value:    strconv.FormatInt(time.Now().Add(r.Window).UnixMilli(), 10),
+		Path:     "/",
+		MaxAge:   int(r.Window.Seconds()) + 1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func key(c *abstraction.Context) string {
+	return redis.Key(c, "read_primary:user:%d", c.Auth.ID)
+}
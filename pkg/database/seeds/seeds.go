@@ -0,0 +1,78 @@
+// Package seeds inserts the data a fresh environment needs: the roles, the
+// root org unit, the bootstrap administrator and, in the local environment,
+// demo users.
+//
+// Seeds are idempotent, they only insert the rows that are missing and never
+// change existing ones, so that they can run on every deploy.
+package seeds
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Seed is a named set of rows.
+type Seed struct {
+	Name string
+	// Envs lists the environments the seed runs in, all of them when empty.
+	Envs []string
+	Run  func(ctx context.Context, db *gorm.DB) error
+}
+
+// All lists the seeds in the order they run, later seeds may rely on the
+// rows of earlier ones.
+var All = []Seed{
+	{Name: "roles", Run: seedRoles},
+	{Name: "org_units", Run: seedOrgUnits},
+	{Name: "admin", Run: seedAdmin},
+	{Name: "demo_users", Envs: []string{"local"}, Run: seedDemoUsers},
+}
+
+// For returns the seeds of env, restricted to names when given.
+func For(env string, names ...string) ([]Seed, error) {
+	for _, name := range names {
+		if !slices.ContainsFunc(All, func(s Seed) bool { return s.Name == name }) {
+			return nil, fmt.Errorf("seeds: unknown seed %q", name)
+		}
+	}
+
+	seeds := make([]Seed, 0, len(All))
+	for _, s := range All {
+		if len(s.Envs) > 0 && !slices.Contains(s.Envs, env) {
+			continue
+		}
+		if len(names) > 0 && !slices.Contains(names, s.Name) {
+			continue
+		}
+		seeds = append(seeds, s)
+	}
+	return seeds, nil
+}
+
+// Run runs the seeds of env on db, each in its own transaction. names
+// restricts the run to some seeds.
+func Run(ctx context.Context, db *gorm.DB, env string, names ...string) error {
+	seeds, err := For(env, names...)
+	if err != nil {
+		return err
+	}
+	for _, s := range seeds {
+		start := time.Now()
+		if err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return s.Run(ctx, tx)
+		}); err != nil {
+			return fmt.Errorf("seeds: %s: %w", s.Name, err)
+		}
+		logrus.WithFields(logrus.Fields{
+			"seed":     s.Name,
+			"env":      env,
+			"duration": time.Since(start).String(),
+		}).Info("seed done")
+	}
+	return nil
+}
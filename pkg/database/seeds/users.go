@@ -0,0 +1,158 @@
+package seeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"boilerplate/internal/config"
+	"boilerplate/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	roleAdministrator = "Administrator"
+	roleUser          = "User"
+
+	// rootOrgUnit is the org unit of the seeded users, the org unit scope of
+	// non-global users sees nothing without one.
+	rootOrgUnit = "Head Office"
+
+	// localPassword is the password of the seeded users of the local
+	// environment.
+	localPassword = "password"
+)
+
+func seedRoles(_ context.Context, db *gorm.DB) error {
+	roles := []model.RoleEntity{
+		{Name: roleAdministrator, Description: ptr("Full access to the console"), IsGlobal: true},
+		{Name: roleUser, Description: ptr("Access to the own org unit")},
+	}
+	for _, role := range roles {
+		if _, err := findOrCreateRole(db, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedOrgUnits(_ context.Context, db *gorm.DB) error {
+	_, err := findOrCreateRootOrgUnit(db)
+	return err
+}
+
+// seedAdmin creates the bootstrap administrator of config.Seed(), which may
+// use a default password in the local environment only.
+func seedAdmin(_ context.Context, db *gorm.DB) error {
+	cfg := config.Seed()
+	password := cfg.AdminPassword
+	if password == "" {
+		if !config.App().IsLocal() {
+			return errors.New("SEED_ADMIN_PASSWORD is required outside the local environment")
+		}
+		password = localPassword
+	}
+
+	role, err := findOrCreateRole(db, model.RoleEntity{Name: roleAdministrator, IsGlobal: true})
+	if err != nil {
+		return err
+	}
+	orgUnit, err := findOrCreateRootOrgUnit(db)
+	if err != nil {
+		return err
+	}
+	return createUser(db, model.UserEntity{
+		Username:  cfg.AdminUsername,
+		Name:      cfg.AdminName,
+		Email:     cfg.AdminEmail,
+		Password:  password,
+		RoleID:    role.ID,
+		OrgUnitID: &orgUnit.ID,
+	})
+}
+
+func seedDemoUsers(_ context.Context, db *gorm.DB) error {
+	role, err := findOrCreateRole(db, model.RoleEntity{Name: roleUser})
+	if err != nil {
+		return err
+	}
+	orgUnit, err := findOrCreateRootOrgUnit(db)
+	if err != nil {
+		return err
+	}
+	for i := 1; i <= 3; i++ {
+		if err = createUser(db, model.UserEntity{
+			Username:  fmt.Sprintf("demo%d", i),
+			Name:      fmt.Sprintf("Demo User %d", i),
+			Email:     fmt.Sprintf("demo%d@localhost", i),
+			Password:  localPassword,
+			RoleID:    role.ID,
+			OrgUnitID: &orgUnit.ID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findOrCreateRole returns the role of the default tenant named like role,
+// creating it when missing.
+func findOrCreateRole(db *gorm.DB, role model.RoleEntity) (*model.RoleEntityModel, error) {
+	data := model.RoleEntityModel{RoleEntity: role}
+	data.TenantID = config.Tenant().Default
+
+	found := db.Where("tenant_id = ? AND LOWER(name) = LOWER(?)", data.TenantID, role.Name).Limit(1).Find(&data)
+	if found.Error != nil || found.RowsAffected > 0 {
+		return &data, found.Error
+	}
+	if err := db.Create(&data).Error; err != nil {
+		return nil, err
+	}
+	logrus.WithField("role", role.Name).Info("seeded role")
+	return &data, nil
+}
+
+// findOrCreateRootOrgUnit returns the root org unit of the default tenant
+// named rootOrgUnit, creating it with its closure row when missing.
+func findOrCreateRootOrgUnit(db *gorm.DB) (*model.OrgUnitEntityModel, error) {
+	data := model.OrgUnitEntityModel{OrgUnitEntity: model.OrgUnitEntity{Name: rootOrgUnit}}
+	data.TenantID = config.Tenant().Default
+
+	found := db.Where("tenant_id = ? AND parent_id IS NULL AND LOWER(name) = LOWER(?)", data.TenantID, rootOrgUnit).Limit(1).Find(&data)
+	if found.Error != nil || found.RowsAffected > 0 {
+		return &data, found.Error
+	}
+	if err := db.Create(&data).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Create(&model.OrgUnitClosureEntityModel{AncestorID: data.ID, DescendantID: data.ID}).Error; err != nil {
+		return nil, err
+	}
+	logrus.WithField("org_unit", rootOrgUnit).Info("seeded org unit")
+	return &data, nil
+}
+
+// createUser creates user in the default tenant unless its username or email
+// is taken. The password is hashed by model.UserEntityModel.BeforeCreate.
+func createUser(db *gorm.DB, user model.UserEntity) error {
+	data := model.UserEntityModel{UserEntity: user}
+	data.TenantID = config.Tenant().Default
+
+	var count int64
+	if err := db.Model(&model.UserEntityModel{}).
+		Where("tenant_id = ? AND (LOWER(username) = LOWER(?) OR LOWER(email) = LOWER(?))", data.TenantID, user.Username, user.Email).
+		Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	if err := db.Create(&data).Error; err != nil {
+		return err
+	}
+	logrus.WithField("username", user.Username).Info("seeded user")
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
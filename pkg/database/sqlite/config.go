@@ -0,0 +1,64 @@
+// Package sqlite opens SQLite databases, for local development and the
+// integration tests. The driver is pure Go and needs no cgo.
+package sqlite
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Memory is the Path of an in-memory database.
+const Memory = ":memory:"
+
+// Config ...
+type Config struct {
+	Name string
+	// Path is the database file, created when missing, or Memory.
+	Path string
+	// BusyTimeout is how long a write waits for the lock held by another one.
+	BusyTimeout time.Duration
+
+	// Logger logs the statements, the default logger of GORM when nil.
+	Logger logger.Interface
+}
+
+// DSN ...
+func (c Config) DSN() string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout("+strconv.FormatInt(c.BusyTimeout.Milliseconds(), 10)+")")
+
+	// the connections of the pool share the in-memory database by its name,
+	// it lives as long as one of them is open
+	if c.Path == Memory {
+		params.Set("mode", "memory")
+		params.Set("cache", "shared")
+		return "file:" + strings.ToLower(c.Name) + "?" + params.Encode()
+	}
+	params.Add("_pragma", "journal_mode(WAL)")
+	return "file:" + c.Path + "?" + params.Encode()
+}
+
+// Open ...
+func (c Config) Open() (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(c.DSN()), &gorm.Config{
+		Logger:                 c.Logger,
+		SkipDefaultTransaction: true,
+		PrepareStmt:            true,
+	})
+	if err != nil {
+		if db != nil {
+			if sqlDB, errDB := db.DB(); errDB == nil {
+				_ = sqlDB.Close()
+			}
+		}
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,245 @@
+// Package dependency connects the application to the systems it depends on,
+// waiting for them at startup and dialling them again when they drop.
+package dependency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	minBackoff   = 500 * time.Millisecond
+	checkTimeout = 10 * time.Second
+)
+
+// State is the state of a dependency.
+type State string
+
+const (
+	StateConnecting State = "connecting"
+	StateUp         State = "up"
+	StateDown       State = "down"
+)
+
+// Dependency is a system the application connects to.
+type Dependency struct {
+	Name string
+	// Optional dependencies don't fail the startup, the application runs
+	// degraded while they are down.
+	Optional bool
+	// Connect establishes the connection. It is called until it succeeds at
+	// startup and again, with a backoff, after Check fails.
+	Connect func(ctx context.Context) error
+	// Check reports whether the established connection still works.
+	Check func(ctx context.Context) error
+}
+
+// Status is the state of a dependency as reported by the health endpoints.
+type Status struct {
+	Name     string    `json:"name"`
+	Optional bool      `json:"optional"`
+	State    State     `json:"state"`
+	Error    string    `json:"error,omitempty"`
+	Since    time.Time `json:"since"`
+	Attempts int       `json:"attempts,omitempty"`
+}
+
+type entry struct {
+	Dependency
+	status      Status
+	backoff     time.Duration
+	nextAttempt time.Time
+}
+
+// Manager connects dependencies in the order they were added, so that a
+// dependency may use the ones added before it, e.g. a database reached
+// through an SSH tunnel.
+type Manager struct {
+	startTimeout  time.Duration
+	checkInterval time.Duration
+	maxBackoff    time.Duration
+
+	mu      sync.RWMutex
+	entries []*entry
+}
+
+// NewManager returns a manager waiting up to startTimeout for the required
+// dependencies and checking them every checkInterval once started.
+func NewManager(startTimeout, checkInterval, maxBackoff time.Duration) *Manager {
+	return &Manager{
+		startTimeout:  startTimeout,
+		checkInterval: checkInterval,
+		maxBackoff:    maxBackoff,
+	}
+}
+
+// Add adds dependencies to the manager.
+func (m *Manager) Add(deps ...Dependency) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range deps {
+		m.entries = append(m.entries, &entry{
+			Dependency: d,
+			status:     Status{Name: d.Name, Optional: d.Optional, State: StateConnecting, Since: time.Now()},
+			backoff:    minBackoff,
+		})
+	}
+}
+
+// Start connects the dependencies. Required dependencies are retried with a
+// backoff until the start timeout, optional ones are tried once and left to
+// Run when down. It fails when a required dependency can't be reached.
+func (m *Manager) Start(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.startTimeout)
+	defer cancel()
+
+	m.mu.RLock()
+	entries := m.entries
+	m.mu.RUnlock()
+
+	for _, e := range entries {
+		for {
+			err := m.connect(ctx, e)
+			if err == nil {
+				break
+			}
+			if e.Optional {
+				logrus.WithField("dependency", e.Name).WithError(err).Warn("optional dependency is down, running degraded")
+				break
+			}
+
+			m.mu.RLock()
+			wait := time.Until(e.nextAttempt)
+			m.mu.RUnlock()
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("dependency %s: %w", e.Name, err)
+			case <-time.After(wait):
+			}
+		}
+	}
+	return nil
+}
+
+// Run checks the dependencies every check interval until ctx is done,
+// connecting again the ones that are down.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.RLock()
+		entries := m.entries
+		m.mu.RUnlock()
+		for _, e := range entries {
+			m.mu.RLock()
+			state, due := e.status.State, !time.Now().Before(e.nextAttempt)
+			m.mu.RUnlock()
+
+			if state == StateUp {
+				m.check(ctx, e)
+			} else if due {
+				_ = m.connect(ctx, e)
+			}
+		}
+	}
+}
+
+// Status returns the state of the dependencies.
+func (m *Manager) Status() []Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	status := make([]Status, 0, len(m.entries))
+	for _, e := range m.entries {
+		status = append(status, e.status)
+	}
+	return status
+}
+
+// Ready reports whether every required dependency is up.
+func (m *Manager) Ready() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, e := range m.entries {
+		if !e.Optional && e.status.State != StateUp {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Manager) connect(ctx context.Context, e *entry) error {
+	attemptCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	err := e.Connect(attemptCtx)
+	if err == nil && e.Check != nil {
+		err = e.Check(attemptCtx)
+	}
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		e.status.Attempts++
+		e.nextAttempt = time.Now().Add(e.backoff)
+		e.backoff = min(e.backoff*2, max(m.maxBackoff, minBackoff))
+		m.setState(e, StateDown, err)
+		return err
+	}
+	e.backoff = minBackoff
+	e.status.Attempts = 0
+	m.setState(e, StateUp, nil)
+	return nil
+}
+
+func (m *Manager) check(ctx context.Context, e *entry) {
+	if e.Check == nil {
+		return
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	err := e.Check(checkCtx)
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		// dial again on the next round
+		e.nextAttempt = time.Now()
+		m.setState(e, StateDown, err)
+	}
+}
+
+// setState records a change of state, m.mu must be held.
+func (m *Manager) setState(e *entry, state State, err error) {
+	log := logrus.WithField("dependency", e.Name)
+	if state != e.status.State {
+		e.status.Since = time.Now()
+		if state == StateUp {
+			log.Info("dependency is up")
+		} else {
+			log.WithError(err).Error("dependency is down")
+		}
+	}
+	e.status.State = state
+	e.status.Error = ""
+	if err != nil {
+		e.status.Error = err.Error()
+	}
+}
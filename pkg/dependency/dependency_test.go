@@ -0,0 +1,46 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManager_Start(t *testing.T) {
+	refused := errors.New("connection refused")
+	failing := func(times int) func(context.Context) error {
+		return func(context.Context) error {
+			if times > 0 {
+				times--
+				return refused
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name      string
+		deps      []Dependency
+		wantErr   bool
+		wantReady bool
+	}{
+		{"up", []Dependency{{Name: "db", Connect: failing(0)}}, false, true},
+		{"retried", []Dependency{{Name: "db", Connect: failing(1)}}, false, true},
+		{"required down", []Dependency{{Name: "db", Connect: failing(100)}}, true, false},
+		{"optional down", []Dependency{{Name: "db", Connect: failing(0)}, {Name: "minio", Optional: true, Connect: failing(100)}}, false, true},
+		{"check fails", []Dependency{{Name: "db", Connect: failing(0), Check: failing(100)}}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(time.Second, time.Second, time.Second)
+			m.Add(tt.deps...)
+			if err := m.Start(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := m.Ready(); got != tt.wantReady {
+				t.Errorf("Ready() = %v, want %v", got, tt.wantReady)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"boilerplate/internal/abstraction"
+	"boilerplate/internal/config"
+	"boilerplate/pkg/dependency"
 	miniodto "boilerplate/pkg/minio/dto"
 )
 
@@ -11,3 +13,46 @@ type Service interface {
 	UploadFile(ctx *abstraction.Context, payload *miniodto.MinioUploadFileRequest) (string, error)
 	ObjectURL(ctx context.Context, payload *miniodto.MinioObjectURLRequest) (string, error)
 }
+
+// ObjectPrefix is the prefix of the objects uploaded by a tenant.
+func ObjectPrefix(tenantID string) string {
+	if tenantID == "" {
+		return ""
+	}
+	return "tenants/" + tenantID + "/"
+}
+
+// Dependencies checks that the storage is reachable through the first
+// bucket, or by listing the buckets when none is configured, and checks its
+// SSH tunnel when there is one.
+func Dependencies() []dependency.Dependency {
+	check := func(ctx context.Context) error {
+		cfg := config.Minio()
+		if cfg.MinioClient == nil {
+			return cfg.Err
+		}
+		if len(cfg.Bucket) == 0 || cfg.Bucket[0] == "" {
+			_, err := cfg.MinioClient.ListBuckets(ctx)
+			return err
+		}
+		_, err := cfg.MinioClient.BucketExists(ctx, cfg.Bucket[0])
+		return err
+	}
+	optional := config.Dependency().IsOptional("minio")
+
+	var deps []dependency.Dependency
+	if t := config.Minio().Tunnel; t != nil {
+		deps = append(deps, dependency.Dependency{
+			Name:     "ssh:minio",
+			Optional: optional,
+			Connect:  t.Connect,
+			Check:    t.Check,
+		})
+	}
+	return append(deps, dependency.Dependency{
+		Name:     "minio",
+		Optional: optional,
+		Connect:  check,
+		Check:    check,
+	})
+}
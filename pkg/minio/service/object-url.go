@@ -2,17 +2,27 @@ package minioservice
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"boilerplate/internal/abstraction"
+	"boilerplate/pkg/minio"
 	miniodto "boilerplate/pkg/minio/dto"
 	"boilerplate/pkg/util/response"
 )
 
-// ObjectURL ...
+// ObjectURL ... When ctx carries a request (see abstraction.Context.RequestContext)
+// only objects of the request's tenant are signed.
 func (s *service) ObjectURL(ctx context.Context, payload *miniodto.MinioObjectURLRequest) (string, error) {
+	if s.Client == nil {
+		return "", response.CustomErrorBuilder(503, "storage is unavailable", "minio_get_object_url")
+	}
 	if payload == nil {
 		return "", response.CustomErrorBuilder(400, "need filter", "need filter")
 	}
+	if c, ok := abstraction.FromContext(ctx); ok && !strings.HasPrefix(payload.FileName, minio.ObjectPrefix(c.TenantID)) {
+		return "", response.CustomErrorBuilder(403, "object belongs to another tenant", "minio_get_object_url")
+	}
 	objectURL, err := s.Client.PresignedGetObject(ctx, payload.Bucket, payload.FileName, time.Minute*15, nil)
 	if err != nil {
 		return "", response.CustomErrorBuilder(500, err.Error(), "minio_get_object_url")
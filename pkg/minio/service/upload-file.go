@@ -7,6 +7,7 @@ import (
 
 	"boilerplate/internal/abstraction"
 	"boilerplate/pkg/mime"
+	"boilerplate/pkg/minio"
 	minioConstant "boilerplate/pkg/minio/constant"
 	miniodto "boilerplate/pkg/minio/dto"
 	"boilerplate/pkg/util/response"
@@ -17,6 +18,10 @@ import (
 
 // UploadFile ...
 func (s *service) UploadFile(ctx *abstraction.Context, payload *miniodto.MinioUploadFileRequest) (string, error) {
+	if s.Client == nil {
+		return "", response.CustomErrorBuilder(503, "storage is unavailable", "upload_file")
+	}
+
 	file, err := ctx.FormFile("file")
 	if err != nil {
 		return "", response.CustomErrorBuilder(500, err.Error(), "upload_file")
@@ -55,9 +60,9 @@ func (s *service) UploadFile(ctx *abstraction.Context, payload *miniodto.MinioUp
 		return "", response.CustomErrorBuilder(500, err.Error(), "open_file")
 	}
 
-	// Generate a unique file name
+	// Generate a unique file name under the tenant's prefix
 	extension := filepath.Ext(file.Filename)
-	uniqueFileName := fmt.Sprintf("%s-%s%s", payload.Bucket, uuid.New().String(), extension)
+	uniqueFileName := fmt.Sprintf("%s%s-%s%s", minio.ObjectPrefix(ctx.TenantID), payload.Bucket, uuid.New().String(), extension)
 
 	if _, err = s.Client.PutObject(
 		ctx.Request().Context(),
@@ -0,0 +1,196 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"boilerplate/internal/config"
+	"boilerplate/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	// lease is how long a claimed event stays hidden from other workers while
+	// it is delivered. Events of a worker that dies are picked up again after.
+	lease = time.Minute
+
+	baseRetryDelay = 5 * time.Second
+	maxRetryDelay  = time.Hour
+)
+
+// Dispatcher delivers pending outbox events to its sinks. Several dispatchers
+// can run against the same table, rows are claimed with SKIP LOCKED.
+type Dispatcher struct {
+	db    *gorm.DB
+	sinks []Sink
+	cfg   *config.OutboxConfig
+	log   *logrus.Entry
+}
+
+func NewDispatcher(db *gorm.DB, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{
+		db:    db.Clauses(dbresolver.Write),
+		sinks: sinks,
+		cfg:   config.Outbox(),
+		log:   logrus.WithField("worker", "outbox"),
+	}
+}
+
+// Run delivers events until ctx is cancelled. It polls every PollInterval and
+// right after a transaction publishing events commits.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.log.WithField("sinks", d.sinkNames()).Info("outbox dispatcher started")
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := d.Dispatch(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				d.log.WithError(err).Error("failed to dispatch outbox events")
+			}
+			// keep going while full batches are claimed
+			if err != nil || n < d.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			d.log.Info("outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// Dispatch claims one batch of due events and delivers them, returning how
+// many were claimed.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	events, err := d.claim(ctx)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	for _, e := range events {
+		if ctx.Err() != nil {
+			// the lease expires and another run picks the event up
+			return len(events), ctx.Err()
+		}
+		if err = d.settle(ctx, e, d.deliver(ctx, e)); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// claim locks a batch of due events and pushes their next attempt past the
+// lease, counting the attempt.
+func (d *Dispatcher) claim(ctx context.Context) (events []*model.OutboxEntityModel, err error) {
+	now := time.Now().UTC()
+	err = d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("status = ? AND next_attempt_date <= ?", model.OutboxStatusPending, now).Order("id").Limit(d.cfg.BatchSize)
+		if tx.Dialector.Name() != "sqlite" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(events))
+		for _, e := range events {
+			e.Attempts++
+			e.NextAttemptDate = now.Add(lease)
+			ids = append(ids, e.ID)
+		}
+		return tx.Model(&model.OutboxEntityModel{}).Where("id IN (?)", ids).Updates(map[string]interface{}{
+			"attempts":          gorm.Expr("attempts + 1"),
+			"next_attempt_date": now.Add(lease),
+		}).Error
+	})
+	return
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, e *model.OutboxEntityModel) error {
+	var errs []string
+	for _, sink := range d.sinks {
+		sinkCtx, cancel := context.WithTimeout(ctx, d.cfg.DeliverTimeout)
+		err := sink.Deliver(sinkCtx, e)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", sink.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// settle records the outcome of a delivery. Failed events are retried with
+// exponential backoff until MaxAttempts, then left dead for inspection.
+func (d *Dispatcher) settle(ctx context.Context, e *model.OutboxEntityModel, deliverErr error) error {
+	now := time.Now().UTC()
+	log := d.log.WithFields(logrus.Fields{"event_id": e.EventID, "event_type": e.EventType, "attempt": e.Attempts})
+	values := map[string]interface{}{}
+
+	switch {
+	case deliverErr == nil:
+		values["status"] = model.OutboxStatusDelivered
+		values["delivered_date"] = now
+		values["last_error"] = ""
+	case e.Attempts >= d.cfg.MaxAttempts:
+		log.WithError(deliverErr).Error("outbox event is dead after too many attempts")
+		values["status"] = model.OutboxStatusDead
+		values["last_error"] = deliverErr.Error()
+	default:
+		next := now.Add(retryDelay(e.Attempts))
+		log.WithError(deliverErr).WithField("next_attempt_date", next).Warn("outbox event delivery failed")
+		values["next_attempt_date"] = next
+		values["last_error"] = deliverErr.Error()
+	}
+
+	// settle even when ctx was cancelled mid delivery, so a delivered event
+	// isn't sent again after the lease
+	return d.db.WithContext(context.WithoutCancel(ctx)).Model(&model.OutboxEntityModel{}).Where("id = ?", e.ID).Updates(values).Error
+}
+
+func (d *Dispatcher) sinkNames() []string {
+	names := make([]string, 0, len(d.sinks))
+	for _, sink := range d.sinks {
+		names = append(names, sink.Name())
+	}
+	return names
+}
+
+func retryDelay(attempts int) time.Duration {
+	if attempts > 20 {
+		return maxRetryDelay
+	}
+	if delay := baseRetryDelay << (attempts - 1); delay < maxRetryDelay {
+		return delay
+	}
+	return maxRetryDelay
+}
+
+// Requeue moves dead events back to pending so the dispatcher retries them.
+func Requeue(ctx context.Context, db *gorm.DB, ids ...int64) (int64, error) {
+	tx := db.WithContext(ctx).Clauses(dbresolver.Write).Model(&model.OutboxEntityModel{}).
+		Where("status = ? AND id IN (?)", model.OutboxStatusDead, ids).
+		Updates(map[string]interface{}{
+			"status":            model.OutboxStatusPending,
+			"attempts":          0,
+			"next_attempt_date": time.Now().UTC(),
+		})
+	return tx.RowsAffected, tx.Error
+}
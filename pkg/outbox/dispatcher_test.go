@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/config"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/database/migrations"
+	"boilerplate/pkg/database/sqlite"
+	"boilerplate/pkg/util/trxmanager"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testEvent struct {
+	ID int `json:"id"`
+}
+
+func (testEvent) EventType() string     { return "test.happened" }
+func (testEvent) AggregateType() string { return "test" }
+func (e testEvent) AggregateID() string { return strconv.Itoa(e.ID) }
+
+// testSink records the events delivered to it and fails with err.
+type testSink struct {
+	delivered []string
+	err       error
+}
+
+func (s *testSink) Name() string { return "test" }
+
+func (s *testSink) Deliver(_ context.Context, e *model.OutboxEntityModel) error {
+	s.delivered = append(s.delivered, e.AggregateID)
+	return s.err
+}
+
+func outboxDB(t *testing.T) *gorm.DB {
+	db, err := sqlite.Config{Name: t.Name(), Path: sqlite.Memory, Logger: logger.Discard}.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, connection := range migrations.Connections() {
+		m, err := migrations.New(connection, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = m.Up(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// publish publishes the events in a transaction, rolled back when fail is
+// set.
+func publish(t *testing.T, db *gorm.DB, fail bool, events ...Event) {
+	ctx := &abstraction.Context{
+		Context:  echo.New().NewContext(httptest.NewRequest("POST", "/", nil), httptest.NewRecorder()),
+		TenantID: "default",
+	}
+	errRollback := errors.New("rollback")
+	err := trxmanager.New(db).WithTrx(ctx, func(ctx *abstraction.Context) error {
+		if err := Publish(ctx, events...); err != nil {
+			return err
+		}
+		if fail {
+			return errRollback
+		}
+		return nil
+	})
+	if fail && !errors.Is(err, errRollback) || !fail && err != nil {
+		t.Fatalf("WithTrx() error = %v", err)
+	}
+}
+
+func outboxRows(t *testing.T, db *gorm.DB) []*model.OutboxEntityModel {
+	var rows []*model.OutboxEntityModel
+	if err := db.Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestPublish(t *testing.T) {
+	db := outboxDB(t)
+
+	publish(t, db, true, testEvent{ID: 1})
+	publish(t, db, false, testEvent{ID: 2}, testEvent{ID: 3})
+
+	rows := outboxRows(t, db)
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want the events of the committed transaction only", len(rows))
+	}
+	for _, row := range rows {
+		if row.Status != model.OutboxStatusPending || row.EventType != "test.happened" || row.TenantID != "default" || row.EventID == "" {
+			t.Errorf("row = %+v, want a pending event of tenant default", row.OutboxEntity)
+		}
+	}
+
+	ctx := &abstraction.Context{Context: echo.New().NewContext(httptest.NewRequest("POST", "/", nil), httptest.NewRecorder())}
+	if err := Publish(ctx, testEvent{ID: 4}); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("Publish() outside a transaction error = %v, want %v", err, ErrNoTransaction)
+	}
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	db := outboxDB(t)
+	publish(t, db, false, testEvent{ID: 1}, testEvent{ID: 2}, testEvent{ID: 3})
+
+	sink := &testSink{}
+	d := NewDispatcher(db, sink)
+	d.cfg = &config.OutboxConfig{BatchSize: 2, MaxAttempts: 3, DeliverTimeout: time.Second}
+
+	for _, want := range []int{2, 1, 0} {
+		if n, err := d.Dispatch(context.Background()); err != nil || n != want {
+			t.Fatalf("Dispatch() = %d, %v, want %d", n, err, want)
+		}
+	}
+	if want := []string{"1", "2", "3"}; !slices.Equal(sink.delivered, want) {
+		t.Errorf("delivered %v, want %v", sink.delivered, want)
+	}
+	for _, row := range outboxRows(t, db) {
+		if row.Status != model.OutboxStatusDelivered || row.DeliveredDate == nil || row.Attempts != 1 {
+			t.Errorf("row = %+v, want delivered on the first attempt", row.OutboxEntity)
+		}
+	}
+}
+
+func TestDispatcher_retry(t *testing.T) {
+	db := outboxDB(t)
+	publish(t, db, false, testEvent{ID: 1})
+
+	sink := &testSink{err: errors.New("unavailable")}
+	d := NewDispatcher(db, sink)
+	d.cfg = &config.OutboxConfig{BatchSize: 10, MaxAttempts: 2, DeliverTimeout: time.Second}
+
+	if n, err := d.Dispatch(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Dispatch() = %d, %v, want 1", n, err)
+	}
+	row := outboxRows(t, db)[0]
+	if row.Status != model.OutboxStatusPending || row.Attempts != 1 || row.LastError != "test: unavailable" || !row.NextAttemptDate.After(time.Now()) {
+		t.Errorf("row = %+v, want pending and retried later", row.OutboxEntity)
+	}
+	// not due before its retry delay
+	if n, err := d.Dispatch(context.Background()); err != nil || n != 0 {
+		t.Errorf("Dispatch() = %d, %v, want 0", n, err)
+	}
+
+	if err := db.Model(&model.OutboxEntityModel{}).Where("id = ?", row.ID).Update("next_attempt_date", time.Now().UTC()).Error; err != nil {
+		t.Fatal(err)
+	}
+	if n, err := d.Dispatch(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Dispatch() = %d, %v, want 1", n, err)
+	}
+	if row = outboxRows(t, db)[0]; row.Status != model.OutboxStatusDead || row.Attempts != 2 {
+		t.Errorf("row = %+v, want dead after MaxAttempts", row.OutboxEntity)
+	}
+
+	if n, err := Requeue(context.Background(), db, row.ID); err != nil || n != 1 {
+		t.Fatalf("Requeue() = %d, %v, want 1", n, err)
+	}
+	sink.err = nil
+	if n, err := d.Dispatch(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Dispatch() = %d, %v, want 1", n, err)
+	}
+	if row = outboxRows(t, db)[0]; row.Status != model.OutboxStatusDelivered || row.LastError != "" {
+		t.Errorf("row = %+v, want delivered after Requeue", row.OutboxEntity)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second, 10: 2560 * time.Second, 11: maxRetryDelay, 64: maxRetryDelay} {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
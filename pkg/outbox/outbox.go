@@ -0,0 +1,78 @@
+// Package outbox implements the transactional outbox: domain events are
+// written to t_outbox in the transaction that produced them and delivered to
+// the sinks by the Dispatcher once committed, at least once.
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"boilerplate/internal/abstraction"
+	"boilerplate/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrNoTransaction is returned by Publish outside trxmanager.WithTrx.
+var ErrNoTransaction = errors.New("outbox: events must be published inside a transaction")
+
+// Event is a domain event. It is marshalled to JSON as the event payload.
+type Event interface {
+	EventType() string
+	AggregateType() string
+	AggregateID() string
+}
+
+// wake nudges the running Dispatcher when events are committed, so they don't
+// wait for the next poll.
+var wake = make(chan struct{}, 1)
+
+// Publish records events in the transaction of ctx. They are only delivered
+// if the transaction commits.
+func Publish(ctx *abstraction.Context, events ...Event) error {
+	if ctx.Trx == nil {
+		return ErrNoTransaction
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	entries := make([]*model.OutboxEntityModel, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		entry := &model.OutboxEntityModel{CreatedDate: now}
+		entry.EventID = uuid.New().String()
+		entry.EventType = event.EventType()
+		entry.AggregateType = event.AggregateType()
+		entry.AggregateID = event.AggregateID()
+		entry.Payload = payload
+		entry.RequestID = ctx.RequestID()
+		entry.TenantID = ctx.TenantID
+		entry.Status = model.OutboxStatusPending
+		entry.NextAttemptDate = now
+		if ctx.Auth != nil {
+			id := ctx.Auth.ID
+			entry.ActorID = &id
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := ctx.Trx.Db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Create(&entries).Error; err != nil {
+		return err
+	}
+	ctx.AfterCommit(notify)
+	return nil
+}
+
+func notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"boilerplate/internal/config"
+	"boilerplate/internal/model"
+	"boilerplate/pkg/circuitbreaker"
+
+	goRedis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Sink delivers outbox events to another system. Deliver may be called more
+// than once for the same event, consumers should deduplicate on EventID.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, e *model.OutboxEntityModel) error
+}
+
+// Envelope is the message sent to the sinks.
+type Envelope struct {
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	ActorID       *int            `json:"actor_id"`
+	RequestID     string          `json:"request_id"`
+	TenantID      string          `json:"tenant_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+func envelope(e *model.OutboxEntityModel) Envelope {
+	return Envelope{
+		EventID:       e.EventID,
+		EventType:     e.EventType,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		ActorID:       e.ActorID,
+		RequestID:     e.RequestID,
+		TenantID:      e.TenantID,
+		OccurredAt:    e.CreatedDate,
+		Payload:       e.Payload,
+	}
+}
+
+// Sinks builds the sinks listed in config.Outbox().Sinks, once the
+// configuration is valid.
+func Sinks(redisClient *goRedis.Client) ([]Sink, error) {
+	cfg := config.Outbox()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("outbox: %w", err)
+	}
+	sinks := make([]Sink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, LogSink{})
+		case "redis":
+			if redisClient == nil {
+				return nil, fmt.Errorf("outbox: redis sink needs a redis client")
+			}
+			sinks = append(sinks, &RedisStreamSink{Client: redisClient, Stream: cfg.RedisStream, MaxLen: cfg.RedisMaxLen})
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, fmt.Errorf("outbox: webhook sink needs OUTBOX_WEBHOOK_URL")
+			}
+			sinks = append(sinks, &WebhookSink{URL: cfg.WebhookURL, Secret: cfg.WebhookSecret, Client: circuitbreaker.NewClient()})
+		default:
+			return nil, fmt.Errorf("outbox: unknown sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// LogSink writes events to the application log.
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Deliver(_ context.Context, e *model.OutboxEntityModel) error {
+	logrus.WithFields(logrus.Fields{
+		"event_id":       e.EventID,
+		"event_type":     e.EventType,
+		"aggregate_type": e.AggregateType,
+		"aggregate_id":   e.AggregateID,
+		"request_id":     e.RequestID,
+		"tenant_id":      e.TenantID,
+		"payload":        string(e.Payload),
+	}).Info("outbox event")
+	return nil
+}
+
+// RedisStreamSink adds events to a Redis stream, trimmed to about MaxLen
+// entries when MaxLen is set.
+type RedisStreamSink struct {
+	Client *goRedis.Client
+	Stream string
+	MaxLen int64
+}
+
+func (s *RedisStreamSink) Name() string {
+	return "redis"
+}
+
+func (s *RedisStreamSink) Deliver(ctx context.Context, e *model.OutboxEntityModel) error {
+	return s.Client.XAdd(ctx, &goRedis.XAddArgs{
+		Stream: s.Stream,
+		MaxLen: s.MaxLen,
+		Approx: s.MaxLen > 0,
+		Values: map[string]interface{}{
+			"event_id":       e.EventID,
+			"event_type":     e.EventType,
+			"aggregate_type": e.AggregateType,
+			"aggregate_id":   e.AggregateID,
+			"request_id":     e.RequestID,
+			"tenant_id":      e.TenantID,
+			"occurred_at":    e.CreatedDate.Format(time.RFC3339Nano),
+			"payload":        string(e.Payload),
+		},
+	}).Err()
+}
+
+// WebhookSink posts the Envelope as JSON to URL. When Secret is set the body
+// is signed with HMAC-SHA256 in the X-Signature header. Any status other than
+// 2xx fails the delivery.
+type WebhookSink struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, e *model.OutboxEntityModel) error {
+	body, err := json.Marshal(envelope(e))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", e.EventID)
+	req.Header.Set("X-Event-Type", e.EventType)
+	if e.TenantID != "" {
+		req.Header.Set("X-Tenant-ID", e.TenantID)
+	}
+	if s.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"boilerplate/internal/abstraction"
+
+	goRedis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// AuthScopeField is the field of the auth_user_id_%d_info hash caching the
+// groups, org unit, global flag and tenant of the user, see
+// middleware.Authentication.
+const AuthScopeField = "scope"
+
+// setAuthScope sets the field in the hash only while the hash exists, so that
+// a session logged out or expired meanwhile isn't brought back without a TTL.
+var setAuthScope = goRedis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+end
+return 0
+`)
+
+// SetAuthScope caches the scope of the user in its session, if it is logged
+// in.
+func SetAuthScope(ctx *abstraction.Context, userID int, scope []byte) error {
+	return setAuthScope.Run(ctx.Request().Context(), redisClient, []string{Key(ctx, "auth_user_id_%d_info", userID)}, AuthScopeField, scope).Err()
+}
+
+// ForgetAuthScope drops the cached scope of the users once the current
+// transaction commits, their next request reads it from the database again.
+func ForgetAuthScope(ctx *abstraction.Context, userIDs ...int) {
+	if len(userIDs) == 0 {
+		return
+	}
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = Key(ctx, "auth_user_id_%d_info", userID)
+	}
+	c := context.WithoutCancel(ctx.Request().Context())
+	ctx.AfterCommit(func() {
+		if _, err := redisClient.Pipelined(c, func(pipe goRedis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.HDel(c, key, AuthScopeField)
+			}
+			return nil
+		}); err != nil {
+			logrus.WithError(err).Warn(fmt.Sprintf("failed to forget the auth scope of users %v", userIDs))
+		}
+	})
+}
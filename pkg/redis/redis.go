@@ -5,16 +5,24 @@ import (
 	"fmt"
 	"time"
 
+	"boilerplate/internal/abstraction"
 	"boilerplate/internal/config"
+	"boilerplate/pkg/dependency"
+	"boilerplate/pkg/tunnel"
 
 	goRedis "github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
-var redisClient *goRedis.Client
+var (
+	redisClient *goRedis.Client
+	redisTunnel *tunnel.Tunnel
+)
 
+// Init creates the client, the server is reached on the first command and
+// dialled again by the pool when it drops, see Dependencies.
 func Init() {
-	redisClient = goRedis.NewClient(&goRedis.Options{
+	options := &goRedis.Options{
 		Addr:            fmt.Sprintf("%v:%v", config.Redis().Host, config.Redis().Port),
 		Password:        config.Redis().Pass,
 		DB:              0,
@@ -23,22 +31,61 @@ func Init() {
 		MaxIdleConns:    5,
 		ConnMaxIdleTime: time.Hour,
 		ConnMaxLifetime: 5 * time.Minute,
-	})
+	}
+	if ssh := config.Redis().SSH; ssh != nil {
+		redisTunnel = tunnel.New("redis", *ssh)
+		options.Dialer = redisTunnel.DialContext
+	}
+	redisClient = goRedis.NewClient(options)
+}
 
-	if err := redisClient.Ping(context.TODO()).Err(); err != nil {
-		panic(fmt.Errorf("failed to connect to redis, error: %v", err))
+// Dependencies checks the connection to the server, and to its SSH tunnel
+// when there is one.
+func Dependencies() []dependency.Dependency {
+	optional := config.Dependency().IsOptional("redis")
+	ping := func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
 	}
 
-	logrus.Info("successfully connected to redis")
+	var deps []dependency.Dependency
+	if redisTunnel != nil {
+		deps = append(deps, dependency.Dependency{
+			Name:     "ssh:redis",
+			Optional: optional,
+			Connect:  redisTunnel.Connect,
+			Check:    redisTunnel.Check,
+		})
+	}
+	return append(deps, dependency.Dependency{
+		Name:     "redis",
+		Optional: optional,
+		Connect:  ping,
+		Check:    ping,
+	})
 }
 
 func Client() *goRedis.Client {
 	return redisClient
 }
 
+// Key formats a key under the tenant of ctx, so that tenants sharing the
+// server never see each other's keys.
+func Key(ctx *abstraction.Context, format string, args ...interface{}) string {
+	key := fmt.Sprintf(format, args...)
+	if ctx == nil || ctx.TenantID == "" {
+		return key
+	}
+	return fmt.Sprintf("tenant:%s:%s", ctx.TenantID, key)
+}
+
 func Close() {
 	if err := redisClient.Close(); err != nil {
 		logrus.WithField("message", "failed to close redis connection").Error(err.Error())
 	}
 	logrus.Info("redis connection to closed")
+	if redisTunnel != nil {
+		if err := redisTunnel.Close(); err != nil {
+			logrus.WithField("message", "failed to close ssh connection redis").Error(err.Error())
+		}
+	}
 }
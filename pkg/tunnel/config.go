@@ -0,0 +1,165 @@
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Config is the SSH server of a tunnel.
+type Config struct {
+	Host string
+	Port string
+	User string
+	// Pass is tried after the keys of the SSH agent and KeyFile.
+	Pass string
+
+	// KeyFile is a private key in PEM or OpenSSH format, encrypted with
+	// KeyPassphrase when set.
+	KeyFile       string
+	KeyPassphrase string
+
+	// KnownHostsFile verifies the host keys, ~/.ssh/known_hosts when unset
+	// and present. HostKeys pins host keys by their SHA256 fingerprint, as
+	// printed by ssh-keygen -l. A host key matching neither is refused unless
+	// InsecureIgnoreHostKey is set.
+	KnownHostsFile        string
+	HostKeys              []string
+	InsecureIgnoreHostKey bool
+
+	// Jump lists the hosts to go through, in order, to reach Host, as
+	// [user@]host[:port]. They share the credentials and host keys settings.
+	Jump []string
+
+	// KeepAliveInterval is how often the connection is pinged, it is closed
+	// after KeepAliveMax pings in a row are unanswered.
+	KeepAliveInterval time.Duration
+	KeepAliveMax      int
+	Timeout           time.Duration
+}
+
+type hop struct {
+	user string
+	addr string
+}
+
+// hops returns the jump hosts followed by Host.
+func (c Config) hops() ([]hop, error) {
+	hops := make([]hop, 0, len(c.Jump)+1)
+	for _, j := range c.Jump {
+		h := hop{user: c.User, addr: j}
+		if i := strings.LastIndex(j, "@"); i >= 0 {
+			h.user, h.addr = j[:i], j[i+1:]
+		}
+		if h.addr == "" {
+			return nil, fmt.Errorf("invalid jump host %q", j)
+		}
+		if _, _, err := net.SplitHostPort(h.addr); err != nil {
+			h.addr = net.JoinHostPort(h.addr, "22")
+		}
+		hops = append(hops, h)
+	}
+	port := c.Port
+	if port == "" {
+		port = "22"
+	}
+	return append(hops, hop{user: c.User, addr: net.JoinHostPort(c.Host, port)}), nil
+}
+
+// auth returns the authentication methods, with the connection to the SSH
+// agent to close once done when there is one.
+func (c Config) auth() ([]ssh.AuthMethod, net.Conn, error) {
+	var (
+		methods   []ssh.AuthMethod
+		agentConn net.Conn
+	)
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			agentConn = conn
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	if c.KeyFile != "" {
+		signer, err := c.signer()
+		if err != nil {
+			if agentConn != nil {
+				_ = agentConn.Close()
+			}
+			return nil, nil, err
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if c.Pass != "" {
+		methods = append(methods, ssh.Password(c.Pass))
+	}
+	if len(methods) == 0 {
+		return nil, nil, errors.New("no SSH agent, key file nor password to authenticate with")
+	}
+	return methods, agentConn, nil
+}
+
+func (c Config) signer() (ssh.Signer, error) {
+	key, err := os.ReadFile(c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	var signer ssh.Signer
+	if c.KeyPassphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(c.KeyPassphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key file %s: %w", c.KeyFile, err)
+	}
+	return signer, nil
+}
+
+// hostKeyCallback accepts the host keys pinned in HostKeys or listed in the
+// known_hosts file.
+func (c Config) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if c.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	path := c.KnownHostsFile
+	if path == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if _, err := os.Stat(filepath.Join(home, ".ssh", "known_hosts")); err == nil {
+				path = filepath.Join(home, ".ssh", "known_hosts")
+			}
+		}
+	}
+	var known ssh.HostKeyCallback
+	if path != "" {
+		var err error
+		if known, err = knownhosts.New(path); err != nil {
+			return nil, err
+		}
+	}
+	if known == nil && len(c.HostKeys) == 0 {
+		return nil, errors.New("no known_hosts file nor host key fingerprint to verify the server with")
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+		if slices.Contains(c.HostKeys, fingerprint) {
+			return nil
+		}
+		if known == nil {
+			return fmt.Errorf("host key %s of %s is not pinned", fingerprint, hostname)
+		}
+		return known(hostname, remote, key)
+	}, nil
+}
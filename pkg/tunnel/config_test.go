@@ -0,0 +1,81 @@
+package tunnel
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestConfig_hops(t *testing.T) {
+	c := Config{Host: "db.internal", Port: "2200", User: "app", Jump: []string{"ops@gateway:2222", "bastion"}}
+	got, err := c.hops()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []hop{
+		{user: "ops", addr: "gateway:2222"},
+		{user: "app", addr: "bastion:22"},
+		{user: "app", addr: "db.internal:2200"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hops() = %v, want %v", got, want)
+	}
+}
+
+func TestConfig_hostKeyCallback(t *testing.T) {
+	newKey := func() ssh.PublicKey {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := ssh.NewPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	known, pinned, unknown := newKey(), newKey(), newKey()
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize("bastion:22")}, known)
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	remote := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 22}
+
+	tests := []struct {
+		name    string
+		config  Config
+		key     ssh.PublicKey
+		wantErr bool
+	}{
+		{"known", Config{KnownHostsFile: knownHosts}, known, false},
+		{"unknown", Config{KnownHostsFile: knownHosts}, unknown, true},
+		{"pinned", Config{KnownHostsFile: knownHosts, HostKeys: []string{ssh.FingerprintSHA256(pinned)}}, pinned, false},
+		{"not pinned", Config{HostKeys: []string{ssh.FingerprintSHA256(pinned)}}, unknown, true},
+		{"insecure", Config{InsecureIgnoreHostKey: true}, unknown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callback, err := tt.config.hostKeyCallback()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := callback("bastion:22", remote, tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("callback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := (Config{}).hostKeyCallback(); err == nil {
+		t.Error("hostKeyCallback() without known_hosts nor host keys succeeded")
+	}
+}
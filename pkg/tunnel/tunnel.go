@@ -0,0 +1,253 @@
+// Package tunnel dials connections through SSH, for the databases, Redis and
+// MinIO servers only reachable through a bastion.
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+var errDown = errors.New("ssh tunnel is down")
+
+// Tunnel is an SSH connection, through the jump hosts of its Config if any,
+// that is dialled again after it drops. Connections dialled through it before
+// the drop fail, the pools using it replace them.
+type Tunnel struct {
+	name   string
+	config Config
+
+	mu     sync.Mutex
+	agent  net.Conn
+	hops   []*ssh.Client
+	client *ssh.Client
+}
+
+// New returns the tunnel named name, it connects on the first Connect or
+// Dial.
+func New(name string, config Config) *Tunnel {
+	return &Tunnel{name: name, config: config}
+}
+
+// Connect opens the SSH connection unless it is open.
+func (t *Tunnel) Connect(ctx context.Context) error {
+	_, err := t.connection(ctx)
+	return err
+}
+
+// Check sends a keepalive through the SSH connection, dropping it when
+// unanswered.
+func (t *Tunnel) Check(ctx context.Context) error {
+	t.mu.Lock()
+	client := t.client
+	t.mu.Unlock()
+	if client == nil {
+		return errDown
+	}
+	if err := keepAlive(ctx, client); err != nil {
+		t.drop(client)
+		return err
+	}
+	return nil
+}
+
+// Dial opens a connection to addr through the tunnel, connecting the tunnel
+// first if needed.
+func (t *Tunnel) Dial(network, addr string) (net.Conn, error) {
+	return t.DialContext(context.Background(), network, addr)
+}
+
+// DialContext is Dial with a context, as used by net/http and go-redis.
+func (t *Tunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, err := t.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.DialContext(ctx, network, addr)
+	// a forward refused by the server, e.g. a database restarting behind the
+	// bastion, leaves the SSH connection and the others dialled through it up
+	var refused *ssh.OpenChannelError
+	if err != nil && ctx.Err() == nil && !errors.As(err, &refused) {
+		t.drop(client)
+	}
+	return conn, err
+}
+
+// Close closes the SSH connection.
+func (t *Tunnel) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closeLocked()
+}
+
+func (t *Tunnel) connection(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	if err := t.connectLocked(ctx); err != nil {
+		_ = t.closeLocked()
+		return nil, fmt.Errorf("connection to ssh %s, error: %w", t.name, err)
+	}
+	logrus.Info(fmt.Sprintf("successfully connected to ssh %s", t.name))
+
+	client, done := t.client, make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(done)
+		if t.drop(client) {
+			logrus.Warn(fmt.Sprintf("ssh connection to %s dropped", t.name))
+		}
+	}()
+	if t.config.KeepAliveInterval > 0 {
+		go t.keepAlive(client, done)
+	}
+	return client, nil
+}
+
+// connectLocked connects to each hop through the previous one, t.mu must be
+// held.
+func (t *Tunnel) connectLocked(ctx context.Context) error {
+	auth, agentConn, err := t.config.auth()
+	if err != nil {
+		return err
+	}
+	t.agent = agentConn
+	hostKeyCallback, err := t.config.hostKeyCallback()
+	if err != nil {
+		return err
+	}
+
+	hops, err := t.config.hops()
+	if err != nil {
+		return err
+	}
+	for _, h := range hops {
+		clientConfig := &ssh.ClientConfig{
+			User:            h.user,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         t.config.Timeout,
+		}
+
+		var conn net.Conn
+		if len(t.hops) == 0 {
+			conn, err = (&net.Dialer{Timeout: t.config.Timeout}).DialContext(ctx, "tcp", h.addr)
+		} else {
+			conn, err = t.hops[len(t.hops)-1].DialContext(ctx, "tcp", h.addr)
+		}
+		if err != nil {
+			return err
+		}
+
+		c, chans, reqs, err := t.handshake(ctx, conn, h.addr, clientConfig)
+		if err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("%s: %w", h.addr, err)
+		}
+		t.hops = append(t.hops, ssh.NewClient(c, chans, reqs))
+	}
+	t.client = t.hops[len(t.hops)-1]
+	return nil
+}
+
+// handshake opens the SSH connection over conn, aborted when ctx is done or
+// after Config.Timeout. The handshake doesn't take a context, nor a deadline
+// through a jump host, conn is closed instead.
+func (t *Tunnel) handshake(ctx context.Context, conn net.Conn, addr string, config *ssh.ClientConfig) (ssh.Conn, <-chan ssh.NewChannel, <-chan *ssh.Request, error) {
+	if t.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.config.Timeout)
+		defer cancel()
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if !stop() {
+		// conn was closed, by now or during the handshake
+		if err == nil {
+			_ = c.Close()
+		}
+		return nil, nil, nil, fmt.Errorf("ssh handshake: %w", ctx.Err())
+	}
+	return c, chans, reqs, err
+}
+
+// keepAlive pings client every KeepAliveInterval until done, closing it
+// after KeepAliveMax pings in a row are unanswered.
+func (t *Tunnel) keepAlive(client *ssh.Client, done <-chan struct{}) {
+	ticker := time.NewTicker(t.config.KeepAliveInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), t.config.KeepAliveInterval)
+		err := keepAlive(ctx, client)
+		cancel()
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if failures++; failures >= max(t.config.KeepAliveMax, 1) {
+			logrus.WithError(err).Warn(fmt.Sprintf("ssh connection to %s stopped answering keepalives", t.name))
+			_ = client.Close()
+			return
+		}
+	}
+}
+
+// drop forgets client if it is still the connection of the tunnel, the next
+// Dial connects again.
+func (t *Tunnel) drop(client *ssh.Client) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != client {
+		return false
+	}
+	_ = t.closeLocked()
+	return true
+}
+
+func (t *Tunnel) closeLocked() error {
+	var err error
+	for i := len(t.hops) - 1; i >= 0; i-- {
+		if errClose := t.hops[i].Close(); errClose != nil && err == nil && i == len(t.hops)-1 {
+			err = errClose
+		}
+	}
+	if t.agent != nil {
+		_ = t.agent.Close()
+	}
+	t.agent, t.hops, t.client = nil, nil, nil
+	return err
+}
+
+func keepAlive(ctx context.Context, client *ssh.Client) error {
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,121 @@
+package tunnel
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// serve runs an SSH server accepting the password "secret", which refuses
+// every forward.
+func serve(t *testing.T) (host, port string, key ssh.PublicKey) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if string(pass) != "secret" {
+				return nil, errors.New("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					_ = ch.Reject(ssh.ConnectionFailed, "connection refused")
+				}
+			}()
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(l.Addr().String())
+	return host, port, signer.PublicKey()
+}
+
+func TestTunnel_DialContext(t *testing.T) {
+	host, port, key := serve(t)
+	tun := New("test", Config{
+		Host:     host,
+		Port:     port,
+		User:     "app",
+		Pass:     "secret",
+		HostKeys: []string{ssh.FingerprintSHA256(key)},
+		Timeout:  time.Second,
+	})
+	defer tun.Close()
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	if err := tun.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	client := tun.client
+
+	var refused *ssh.OpenChannelError
+	if _, err := tun.Dial("tcp", "db:5432"); !errors.As(err, &refused) {
+		t.Fatalf("Dial() error = %v, want *ssh.OpenChannelError", err)
+	}
+	if tun.client != client {
+		t.Error("Dial() dropped the SSH connection on a refused forward")
+	}
+}
+
+func TestTunnel_handshakeTimeout(t *testing.T) {
+	// accepts the connections but never answers the handshake
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	tun := New("test", Config{Host: host, Port: port, User: "app", Pass: "secret", InsecureIgnoreHostKey: true, Timeout: 100 * time.Millisecond})
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	start := time.Now()
+	_, err = tun.Dial("tcp", "db:5432")
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("Dial() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Dial() took %s", elapsed)
+	}
+}
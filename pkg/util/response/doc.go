@@ -18,6 +18,15 @@ type ErrorResponse401 struct {
 	Error interface{} `json:"data"`
 }
 
+// ErrorResponse403 ...
+type ErrorResponse403 struct {
+	Meta struct {
+		Success bool   `json:"success" example:"false"`
+		Message string `json:"message" example:"Forbidden access"`
+	} `json:"meta"`
+	Error string `json:"data" example:"forbidden"`
+}
+
 // ErrorResponse404 ...
 type ErrorResponse404 struct {
 	Meta struct {
@@ -27,6 +36,15 @@ type ErrorResponse404 struct {
 	Error interface{} `json:"data"`
 }
 
+// ErrorResponse409 ...
+type ErrorResponse409 struct {
+	Meta struct {
+		Success bool   `json:"success" example:"false"`
+		Message string `json:"message" example:"Created value already exists"`
+	} `json:"meta"`
+	Error string `json:"data" example:"duplicate"`
+}
+
 // ErrorResponse422 ...
 type ErrorResponse422 struct {
 	Meta struct {
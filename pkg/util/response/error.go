@@ -9,6 +9,9 @@ import (
 	"strconv"
 	"strings"
 
+	"boilerplate/internal/abstraction"
+	"boilerplate/pkg/database/dberror"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/go-resty/resty/v2"
 	"github.com/labstack/echo/v4"
@@ -30,6 +33,7 @@ type Error struct {
 
 const (
 	E_DUPLICATE            = "duplicate"
+	E_CONFLICT             = "conflict"
 	E_NOT_FOUND            = "not_found"
 	E_UNPROCESSABLE_ENTITY = "unprocessable_entity"
 	E_UNAUTHORIZED         = "unauthorized"
@@ -42,6 +46,7 @@ const (
 
 type errorConstant struct {
 	Duplicate               Error
+	Conflict                Error
 	NotFound                Error
 	RouteNotFound           Error
 	UnprocessableEntity     Error
@@ -92,6 +97,16 @@ var (
 			},
 			Code: http.StatusConflict,
 		},
+		Conflict: Error{
+			Response: errorResponse{
+				Meta: Meta{
+					Success: false,
+					Message: "Conflict with the current state of the data",
+				},
+				Error: E_CONFLICT,
+			},
+			Code: http.StatusConflict,
+		},
 		NotFound: Error{
 			Response: errorResponse{
 				Meta: Meta{
@@ -298,6 +313,58 @@ func ErrorBadRequest(err error) *Error {
 	return ErrorBuilder(&ErrorConstant.BadRequest, err, err.Error())
 }
 
+// ErrorQuery maps the errors raised while reading list query parameters
+// (filters, sort, fieldsets and cursor) to a validation error listing the allowed
+// values. It returns nil for any other error.
+func ErrorQuery(err error) *Error {
+	var (
+		filterErr   *abstraction.FilterError
+		sortErr     *abstraction.SortError
+		fieldsetErr *abstraction.FieldsetError
+	)
+	switch {
+	case errors.As(err, &filterErr):
+		return ErrorBuilder(&ErrorConstant.Validation, err, map[string]interface{}{filterErr.Param: filterErr.Allowed})
+	case errors.As(err, &sortErr):
+		return ErrorBuilder(&ErrorConstant.Validation, err, map[string]interface{}{sortErr.Param: sortErr.Allowed})
+	case errors.As(err, &fieldsetErr):
+		return ErrorBuilder(&ErrorConstant.Validation, err, map[string]interface{}{fieldsetErr.Param: fieldsetErr.Allowed})
+	case errors.Is(err, abstraction.ErrInvalidCursor):
+		return ErrorBuilder(&ErrorConstant.Validation, err)
+	}
+	return nil
+}
+
+// ErrorDatabase maps the constraint violations, conflicts and timeouts of
+// the database to a response naming the offending field, see dberror.Parse.
+// Retryable errors carry a Retry-After header. It returns nil for any other
+// error.
+func ErrorDatabase(err error) *Error {
+	e := dberror.Parse(err)
+	if e == nil {
+		return nil
+	}
+	switch e.Kind {
+	case dberror.Duplicate:
+		return ErrorBuilder(&ErrorConstant.Duplicate, err, map[string]interface{}{e.Field(): "has already been taken!"})
+	case dberror.Referenced:
+		return ErrorBuilder(&ErrorConstant.Conflict, err, map[string]interface{}{e.Field(): "is still in use!"})
+	case dberror.Missing:
+		return ErrorBuilder(&ErrorConstant.Validation, err, map[string]interface{}{e.Field(): "does not exist!"})
+	case dberror.Invalid:
+		return ErrorBuilder(&ErrorConstant.Validation, err, map[string]interface{}{e.Field(): "is invalid!"})
+	}
+
+	// a new error, the header must not leak into the shared constants
+	res := CustomErrorBuilder(http.StatusConflict, E_CONFLICT, "Concurrent update, please retry", map[string]interface{}{"retryable": true})
+	if e.Kind == dberror.Timeout {
+		res = CustomErrorBuilder(http.StatusServiceUnavailable, E_SERVER_ERROR, "Database is busy, please retry", map[string]interface{}{"retryable": true})
+	}
+	res.ErrorMessage = err
+	res.Header = &http.Header{echo.HeaderRetryAfter: []string{"1"}}
+	return res
+}
+
 func CustomErrorBuilder(code int, err interface{}, message string, vals ...interface{}) *Error {
 	return &Error{
 		Response: errorResponse{
@@ -346,6 +413,8 @@ func ErrorResponse(err error) *Error {
 	var re *Error
 	if errors.As(err, &re) {
 		return re
+	} else if errDB := ErrorDatabase(err); errDB != nil {
+		return errDB
 	} else {
 		return ErrorBuilder(&ErrorConstant.InternalServerError, err)
 	}
@@ -358,6 +427,12 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("error code '%d' because: %s", e.Code, e.ErrorMessage.Error())
 }
 
+// Unwrap returns the underlying error, so errors.Is and errors.As can see
+// through response errors.
+func (e *Error) Unwrap() error {
+	return e.ErrorMessage
+}
+
 func (e *Error) ParseToError() error {
 	return e
 }
@@ -1,55 +1,221 @@
 package trxmanager
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"math/rand"
 	"runtime/debug"
+	"time"
 
 	"boilerplate/internal/abstraction"
+	"boilerplate/pkg/database/dberror"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"gorm.io/plugin/dbresolver"
 )
 
+const (
+	defaultAttempts = 3
+	baseBackoff     = 50 * time.Millisecond
+	maxBackoff      = time.Second
+)
+
 type trxManager struct {
 	db *gorm.DB
 }
 
 type trxFn func(ctx *abstraction.Context) error
 
+type options struct {
+	txOptions sql.TxOptions
+	attempts  int
+}
+
+// Option configures a transaction started by WithTrx. Options only apply to
+// the outermost transaction, nested calls run in a savepoint of it.
+type Option func(o *options)
+
+// Isolation sets the isolation level of the transaction.
+func Isolation(level sql.IsolationLevel) Option {
+	return func(o *options) {
+		o.txOptions.Isolation = level
+	}
+}
+
+// ReadOnly starts a read only transaction.
+func ReadOnly() Option {
+	return func(o *options) {
+		o.txOptions.ReadOnly = true
+	}
+}
+
+// Attempts sets how many times the transaction runs when it fails on a
+// serialization failure or deadlock, 1 disables retries. Defaults to 3.
+func Attempts(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.attempts = n
+		}
+	}
+}
+
 func New(db *gorm.DB) *trxManager {
 	return &trxManager{db}
 }
 
-func (g *trxManager) WithTrx(pCtx *abstraction.Context, fn trxFn) (err error) {
+// WithTrx runs fn in a transaction stored on pCtx. When pCtx already holds a
+// transaction fn runs in a savepoint, so its failure only rolls back its own
+// work. The outermost transaction is retried with backoff on serialization
+// failures and deadlocks, fn must therefore be safe to run again.
+func (g *trxManager) WithTrx(pCtx *abstraction.Context, fn trxFn, opts ...Option) (err error) {
 	if pCtx.Trx != nil {
-		return fn(pCtx)
+		return g.withSavepoint(pCtx, fn)
 	}
 
-	pCtx.Trx = &abstraction.TrxContext{
-		Db: g.db.WithContext(pCtx.Request().Context()).Clauses(dbresolver.Write).Begin(),
+	o := options{attempts: defaultAttempts}
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	fmt.Printf("\nBEGIN\n")
+	log := logrus.WithField("request_id", pCtx.RequestID())
+	for attempt := 1; ; attempt++ {
+		if err = g.run(pCtx, fn, &o.txOptions, log); err == nil || attempt >= o.attempts || !IsRetryable(err) {
+			return err
+		}
+
+		wait := backoff(attempt)
+		log.WithError(err).WithFields(logrus.Fields{"attempt": attempt, "wait": wait}).Warn("transaction failed, retrying")
+		if sleep(pCtx.Request().Context(), wait) != nil {
+			return err
+		}
+	}
+}
+
+func (g *trxManager) run(pCtx *abstraction.Context, fn trxFn, txOptions *sql.TxOptions, log *logrus.Entry) (err error) {
+	// read only transactions may run on a replica
+	resolver := dbresolver.Write
+	if txOptions.ReadOnly && pCtx.UseReplica() {
+		resolver = dbresolver.Read
+	}
+	trx := &abstraction.TrxContext{
+		Db: g.db.WithContext(pCtx.Request().Context()).Clauses(resolver).Begin(txOptions),
+	}
+	if err = trx.Db.Error; err != nil {
+		return err
+	}
+	pCtx.Trx = trx
+	log.Debug("BEGIN")
 
 	defer func() {
 		if p := recover(); p != nil {
-			// a panic occurred, rollback and repanic
-			pCtx.Trx.Db.Rollback()
-			fmt.Printf("\nROLLBACK\n\n")
+			// a panic occurred, rollback and return it as an error
 			logrus.Error(p)
 			err = fmt.Errorf("panic happened because: %s, stacktrace: %s", fmt.Sprintf("%v", p), string(debug.Stack()))
-		} else if err != nil {
-			// error occurred, rollback
-			pCtx.Trx.Db.Rollback()
-			fmt.Printf("\nROLLBACK\n\n")
-		} else {
-			// all good, commit
-			err = pCtx.Trx.Db.Commit().Error
-			fmt.Printf("\nCOMMIT\n\n")
 		}
 		pCtx.Trx = nil
+
+		if err == nil {
+			// all good, commit
+			if err = trx.Db.Commit().Error; err == nil {
+				log.Debug("COMMIT")
+				if !txOptions.ReadOnly {
+					pCtx.MarkWrite()
+				}
+				runHooks(trx.OnCommit, log)
+				return
+			}
+		}
+
+		trx.Db.Rollback()
+		log.WithError(err).Debug("ROLLBACK")
+		runHooks(trx.OnRollback, log)
 	}()
 
 	return fn(pCtx)
 }
+
+func (g *trxManager) withSavepoint(pCtx *abstraction.Context, fn trxFn) (err error) {
+	trx := pCtx.Trx
+	trx.Depth++
+	var (
+		name       = fmt.Sprintf("sp_%d", trx.Depth)
+		onCommit   = len(trx.OnCommit)
+		onRollback = len(trx.OnRollback)
+		log        = logrus.WithFields(logrus.Fields{"request_id": pCtx.RequestID(), "savepoint": name})
+	)
+	if err = trx.Db.SavePoint(name).Error; err != nil {
+		trx.Depth--
+		return err
+	}
+	log.Debug("SAVEPOINT")
+
+	defer func() {
+		if p := recover(); p != nil {
+			logrus.Error(p)
+			err = fmt.Errorf("panic happened because: %s, stacktrace: %s", fmt.Sprintf("%v", p), string(debug.Stack()))
+		}
+		trx.Depth--
+
+		if err == nil {
+			if err = trx.Db.Exec("RELEASE SAVEPOINT " + name).Error; err == nil {
+				log.Debug("RELEASE SAVEPOINT")
+				return
+			}
+		}
+
+		if errRollback := trx.Db.RollbackTo(name).Error; errRollback != nil {
+			log.WithError(errRollback).Error("rollback to savepoint failed")
+		}
+		log.WithError(err).Debug("ROLLBACK TO SAVEPOINT")
+
+		// drop the hooks of the rolled back work
+		hooks := trx.OnRollback[onRollback:]
+		trx.OnCommit = trx.OnCommit[:onCommit]
+		trx.OnRollback = trx.OnRollback[:onRollback]
+		runHooks(hooks, log)
+	}()
+
+	return fn(pCtx)
+}
+
+// IsRetryable reports whether err is a serialization failure or deadlock,
+// after which the whole transaction can run again, see dberror.Conflict.
+func IsRetryable(err error) bool {
+	return dberror.Is(err, dberror.Conflict)
+}
+
+func runHooks(hooks []func(), log *logrus.Entry) {
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					log.WithField("stacktrace", string(debug.Stack())).Errorf("transaction hook panicked: %v", p)
+				}
+			}()
+			hook()
+		}()
+	}
+}
+
+// backoff doubles the wait on each attempt, with jitter so that conflicting
+// transactions don't retry in lockstep.
+func backoff(attempt int) time.Duration {
+	wait := maxBackoff
+	if attempt < 16 && baseBackoff<<(attempt-1) < maxBackoff {
+		wait = baseBackoff << (attempt - 1)
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
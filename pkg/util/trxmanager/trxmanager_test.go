@@ -0,0 +1,58 @@
+package trxmanager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"boilerplate/pkg/util/response"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", sqlStateError("40001"), true},
+		{"deadlock", sqlStateError("40P01"), true},
+		{"unique violation", sqlStateError("23505"), false},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, true},
+		{"mysql duplicate", &mysql.MySQLError{Number: 1062}, false},
+		{"wrapped", fmt.Errorf("update: %w", sqlStateError("40001")), true},
+		{"response error", response.ErrorBuilder(&response.ErrorConstant.UnprocessableEntity, sqlStateError("40P01")), true},
+		{"plain", errors.New("40001"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		want := baseBackoff << (attempt - 1)
+		if want > maxBackoff {
+			want = maxBackoff
+		}
+		if got := backoff(attempt); got < want/2 || got > want {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, got, want/2, want)
+		}
+	}
+	for _, attempt := range []int{40, 63, 64} {
+		if got := backoff(attempt); got < maxBackoff/2 || got > maxBackoff {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, got, maxBackoff/2, maxBackoff)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"boilerplate/internal/config"
+	"boilerplate/internal/factory"
+	"boilerplate/pkg/database"
+	"boilerplate/pkg/database/seeds"
+)
+
+const seedUsage = `usage: seed [--only name,...] [--list]
+
+Inserts the missing bootstrap data of the environment set by ENV, run it
+after the migrations.
+`
+
+// runSeed runs the seed subcommand with the arguments following it.
+func runSeed(args []string) error {
+	var (
+		only string
+		list bool
+	)
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fs.StringVar(&only, "only", "", "comma separated seeds to run")
+	fs.BoolVar(&list, "list", false, "list the seeds of the environment")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, seedUsage) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %s", strings.Join(fs.Args(), " "))
+	}
+
+	var names []string
+	for _, name := range strings.Split(only, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	env := config.App().ENV
+	selected, err := seeds.For(env, names...)
+	if err != nil {
+		return err
+	}
+	if list {
+		for _, s := range selected {
+			fmt.Println(s.Name)
+		}
+		return nil
+	}
+
+	defer database.Close()
+	if err := database.Init(); err != nil {
+		return err
+	}
+
+	// the callbacks of the application, seeded rows are audited too
+	f := new(factory.Factory)
+	f.SetupDB()
+	return seeds.Run(context.Background(), f.DB, env, names...)
+}